@@ -1,10 +1,5 @@
 package mlog
 
-import (
-	"bytes"
-	"io"
-)
-
 // 0, Black; 1, Red; 2, Green; 3, Yellow; 4, Blue; 5, Purple; 6, Cyan; 7, White
 var (
 	colorDebug = []byte("\033[32m")
@@ -14,36 +9,18 @@ var (
 	colorReset = []byte("\033[0m")
 )
 
-type ansiTerm struct {
-	w io.Writer
-}
-
-func (t *ansiTerm) Write(b []byte) (n int, err error) {
-	n = len(b)
-	var cb []byte
-	if n > 20 {
-		switch b[20] {
-		case 'D':
-			cb = colorDebug
-		case 'T':
-			cb = colorTrace
-		case 'W':
-			cb = colorWarn
-		case 'E':
-			cb = colorError
-		case 'F':
-			cb = colorError
-		}
+// appendColor appends the line b in the console color of the level to buf.
+// The line is appended as is if the level has no color.
+func appendColor(buf []byte, level string, b []byte) []byte {
+	color := levelOf(ltoi(level)).Color
+	if color == "" {
+		return append(buf, b...)
 	}
-	if len(cb) == 0 {
-		_, err = t.w.Write(b)
-		return
+	for len(b) > 0 && (b[len(b)-1] == '\n' || b[len(b)-1] == '\r') {
+		b = b[:len(b)-1]
 	}
-	var bb []byte
-	bb = append(bb, cb...)
-	bb = append(bb, bytes.Trim(b, "\r\n")...)
-	bb = append(bb, colorReset...)
-	bb = append(bb, '\n')
-	_, err = t.w.Write(bb)
-	return
+	buf = append(buf, color...)
+	buf = append(buf, b...)
+	buf = append(buf, colorReset...)
+	return append(buf, '\n')
 }
@@ -0,0 +1,139 @@
+package mlog
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// DefaultQueueSize is the queue size of AsyncSink if not given.
+const DefaultQueueSize = 1024
+
+// AsyncSink writes entries to the underlying sink in a background goroutine,
+// so the logging goroutines are not blocked by a slow output. Entries are
+// queued in a bounded ring buffer, and dropped if the queue is full.
+type AsyncSink struct {
+	sink Sink
+
+	mu      sync.Mutex
+	cond    *sync.Cond // signals queue changes
+	queue   []*Entry   // ring buffer
+	head    int
+	count   int
+	busy    bool // entries are being written out of the queue
+	closed  bool
+	done    chan struct{}
+	dropped atomic.Uint64
+}
+
+// NewAsyncSink returns a sink which queues up to size entries for sink.
+func NewAsyncSink(sink Sink, size int) *AsyncSink {
+	if size <= 0 {
+		size = DefaultQueueSize
+	}
+	s := &AsyncSink{
+		sink:  sink,
+		queue: make([]*Entry, size),
+		done:  make(chan struct{}),
+	}
+	s.cond = sync.NewCond(&s.mu)
+	go s.run()
+	return s
+}
+
+func (s *AsyncSink) run() {
+	defer close(s.done)
+
+	batch := make([]*Entry, 0, len(s.queue))
+	for {
+		s.mu.Lock()
+		for s.count == 0 && !s.closed {
+			s.cond.Wait()
+		}
+		if s.count == 0 && s.closed {
+			s.mu.Unlock()
+			return
+		}
+		batch = batch[:0]
+		for ; s.count > 0; s.count-- {
+			batch = append(batch, s.queue[s.head])
+			s.queue[s.head] = nil
+			s.head = (s.head + 1) % len(s.queue)
+		}
+		s.busy = true
+		s.mu.Unlock()
+
+		for _, e := range batch {
+			s.sink.Write(e)
+		}
+
+		s.mu.Lock()
+		s.busy = false
+		s.cond.Broadcast()
+		s.mu.Unlock()
+	}
+}
+
+func (s *AsyncSink) Level() int {
+	return s.sink.Level()
+}
+
+// SetLevel changes the level of the underlying sink if supported.
+func (s *AsyncSink) SetLevel(level string) {
+	if ls, ok := s.sink.(LevelSetter); ok {
+		ls.SetLevel(level)
+	}
+}
+
+// Write queues the entry. It never blocks; the entry is dropped and counted
+// if the queue is full or the sink is closed.
+func (s *AsyncSink) Write(e *Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed || s.count == len(s.queue) {
+		s.dropped.Add(1)
+		return nil
+	}
+	s.queue[(s.head+s.count)%len(s.queue)] = e
+	s.count++
+	s.cond.Broadcast()
+	return nil
+}
+
+// wait blocks until the queue is drained.
+func (s *AsyncSink) wait() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for (s.count > 0 || s.busy) && !s.closed {
+		s.cond.Wait()
+	}
+}
+
+// Flush waits until all queued entries are written, then flushes the
+// underlying sink.
+func (s *AsyncSink) Flush() error {
+	s.wait()
+	return s.sink.Flush()
+}
+
+// Close writes all queued entries, stops the background goroutine, and
+// closes the underlying sink.
+func (s *AsyncSink) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	s.cond.Broadcast()
+	s.mu.Unlock()
+
+	<-s.done
+	return s.sink.Close()
+}
+
+// Dropped returns the number of entries dropped as the queue was full.
+func (s *AsyncSink) Dropped() uint64 {
+	return s.dropped.Load()
+}
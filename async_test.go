@@ -0,0 +1,61 @@
+package mlog
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// blockSink blocks writing until unblocked.
+type blockSink struct {
+	memSink
+	mu sync.Mutex
+}
+
+func (s *blockSink) Write(e *Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.memSink.Write(e)
+}
+
+func TestAsyncSink(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogger("async: ", &Settings{})
+	l.AddSink("buf", NewAsyncSink(NewWriterSink(&buf, Ldebug, 0), 0))
+	for i := 0; i < 10; i++ {
+		l.Infof("line %d", i)
+	}
+	l.Flush()
+	if want, got := 10, bytes.Count(buf.Bytes(), []byte("\n")); want != got {
+		t.Errorf("async flush should write %d lines, is %d", want, got)
+	}
+	l.Info("last line")
+	l.Close()
+	if want, got := "INFO async: last line\n", buf.String()[buf.Len()-22:]; want != got {
+		t.Errorf("async close should write %q, is %q", want, got)
+	}
+}
+
+func TestAsyncSinkDropped(t *testing.T) {
+	bs := &blockSink{}
+	l := NewLogger("async: ", &Settings{})
+	as := NewAsyncSink(bs, 4)
+	l.AddSink("mem", as)
+
+	bs.mu.Lock()
+	for i := 0; i < 100; i++ {
+		l.Info(fmt.Sprint("line ", i))
+	}
+	bs.mu.Unlock()
+	l.Flush()
+	dropped := l.Dropped()
+	l.Close()
+
+	if got := len(bs.entries) + int(dropped); got != 100 {
+		t.Errorf("written and dropped should be 100, is %d", got)
+	}
+	if dropped == 0 || len(bs.entries) > 8 {
+		t.Errorf("full queue should drop, written %d dropped %d", len(bs.entries), dropped)
+	}
+}
@@ -0,0 +1,242 @@
+package mlog
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Caller is how encoders write the caller of entries.
+type Caller int
+
+const (
+	// CallerDefault is the std log flags for TextEncoder, CallerShort for
+	// the others.
+	CallerDefault Caller = iota
+	CallerNone
+	CallerShort   // file.go:12
+	CallerPackage // github.com/ccpaging/mlog/file/file.go:12
+	CallerModule  // path relative to module root, like file/file.go:12
+	CallerFull    // /home/user/src/mlog/file.go:12
+)
+
+// parseCaller returns the caller mode by name, like "short".
+func parseCaller(s string) (Caller, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "default":
+		return CallerDefault, nil
+	case "none":
+		return CallerNone, nil
+	case "short":
+		return CallerShort, nil
+	case "package":
+		return CallerPackage, nil
+	case "module":
+		return CallerModule, nil
+	case "full", "long":
+		return CallerFull, nil
+	}
+	return CallerDefault, errors.New("mlog: unknown caller " + strconv.Quote(s))
+}
+
+// ModulePath is the module path which CallerModule is relative to, if the
+// source of the caller is not found, like built with -trimpath. It is the
+// main module of the program by default.
+var ModulePath = mainModulePath()
+
+func mainModulePath() string {
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		return bi.Main.Path
+	}
+	return ""
+}
+
+// sourceModule is the module of a source directory.
+type sourceModule struct {
+	root string // directory of go.mod, "" if not found
+	path string // module path, "" for the standard library
+}
+
+// sourceModules caches the modules by source directory.
+var sourceModules sync.Map
+
+// moduleOf returns the module of the source directory dir, by the nearest
+// go.mod in dir or its parents.
+func moduleOf(dir string) sourceModule {
+	if m, ok := sourceModules.Load(dir); ok {
+		return m.(sourceModule)
+	}
+	var m sourceModule
+	if b, err := os.ReadFile(filepath.Join(dir, "go.mod")); err == nil {
+		m = sourceModule{root: dir, path: modulePath(b)}
+		if m.path == "std" {
+			m.path = ""
+		}
+	} else if parent := filepath.Dir(dir); parent != dir {
+		m = moduleOf(parent)
+	}
+	sourceModules.Store(dir, m)
+	return m
+}
+
+// modulePath returns the module path of the go.mod file content.
+func modulePath(gomod []byte) string {
+	for _, line := range strings.Split(string(gomod), "\n") {
+		line, _, _ = strings.Cut(line, "//")
+		if rest, ok := strings.CutPrefix(strings.TrimSpace(line), "module"); ok && rest != "" && (rest[0] == ' ' || rest[0] == '\t') {
+			return strings.Trim(strings.TrimSpace(rest), `"`+"`")
+		}
+	}
+	return ""
+}
+
+// packagePath returns the import path of the package of the function,
+// like "github.com/ccpaging/mlog/file" for
+// "github.com/ccpaging/mlog/file.(*File).Write". The dots escaped in the
+// last element, like "gopkg.in/yaml%2ev3", are unescaped.
+func packagePath(function string) string {
+	slash := strings.LastIndexByte(function, '/')
+	if i := strings.IndexByte(function[slash+1:], '.'); i >= 0 {
+		function = function[:slash+1+i]
+	}
+	return strings.ReplaceAll(function, "%2e", ".")
+}
+
+// callerPaths returns the path of the source file relative to its module
+// root, and the module path, like "file/file.go" and
+// "github.com/ccpaging/mlog". If the source is not found, they are from the
+// file path built with -trimpath, or else the package of the function.
+func callerPaths(frame runtime.Frame) (rel, module string) {
+	if filepath.IsAbs(frame.File) {
+		if m := moduleOf(filepath.Dir(frame.File)); m.root != "" {
+			if r, err := filepath.Rel(m.root, frame.File); err == nil {
+				return filepath.ToSlash(r), m.path
+			}
+		}
+	} else if ModulePath != "" && strings.HasPrefix(frame.File, ModulePath+"/") {
+		return frame.File[len(ModulePath)+1:], ModulePath
+	} else if strings.Contains(frame.File, "/") {
+		return frame.File, ""
+	}
+	file := filepath.Base(frame.File)
+	pkg := packagePath(frame.Function)
+	if pkg == "main" || pkg == "" {
+		return file, ""
+	}
+	if ModulePath != "" && pkg == ModulePath {
+		return file, ModulePath
+	}
+	if ModulePath != "" && strings.HasPrefix(pkg, ModulePath+"/") {
+		return pkg[len(ModulePath)+1:] + "/" + file, ModulePath
+	}
+	return pkg + "/" + file, ""
+}
+
+// shortFunction returns the function name without the package directory,
+// like "file.(*File).Write".
+func shortFunction(function string) string {
+	return function[strings.LastIndexByte(function, '/')+1:]
+}
+
+// formatCaller returns the caller of the frame as file:line in mode, followed
+// by the function name if fn.
+func formatCaller(frame runtime.Frame, mode Caller, fn bool) string {
+	file := frame.File
+	switch mode {
+	case CallerShort:
+		file = filepath.Base(file)
+	case CallerPackage, CallerModule:
+		rel, module := callerPaths(frame)
+		file = rel
+		if mode == CallerPackage && module != "" {
+			file = module + "/" + rel
+		}
+	}
+	s := file + ":" + strconv.Itoa(frame.Line)
+	if fn && frame.Function != "" {
+		s += " " + shortFunction(frame.Function)
+	}
+	return s
+}
+
+// caller returns the caller of the entry in mode, or "" if none.
+func (e *Entry) caller(mode Caller, fn bool) string {
+	if mode == CallerNone || e.PC == 0 {
+		return ""
+	}
+	return formatCaller(e.Frame(), mode, fn)
+}
+
+// maxStackDepth is the maximum number of frames in the stack trace.
+const maxStackDepth = 32
+
+// StackFrames returns the frames of the stack trace of the entry.
+func (e *Entry) StackFrames() []runtime.Frame {
+	return stackFrames(e.Stack)
+}
+
+func stackFrames(pcs []uintptr) []runtime.Frame {
+	if len(pcs) == 0 {
+		return nil
+	}
+	var frames []runtime.Frame
+	it := runtime.CallersFrames(pcs)
+	for {
+		frame, more := it.Next()
+		frames = append(frames, frame)
+		if !more {
+			break
+		}
+	}
+	return frames
+}
+
+// stackLines returns the stack trace, one "function file:line" per frame.
+func stackLines(pcs []uintptr) []string {
+	frames := stackFrames(pcs)
+	lines := make([]string, len(frames))
+	for i, frame := range frames {
+		lines[i] = frame.Function + " " + frame.File + ":" + strconv.Itoa(frame.Line)
+	}
+	return lines
+}
+
+// appendStackText appends the stack trace as the block of lines, like
+//
+//	github.com/ccpaging/mlog.TestStack
+//		/home/user/src/mlog/caller_test.go:12
+func appendStackText(buf []byte, e *Entry) []byte {
+	for _, frame := range e.StackFrames() {
+		buf = append(buf, '\t')
+		buf = append(buf, frame.Function...)
+		buf = append(buf, "\n\t\t"...)
+		buf = append(buf, frame.File...)
+		buf = append(buf, ':')
+		buf = strconv.AppendInt(buf, int64(frame.Line), 10)
+		buf = append(buf, '\n')
+	}
+	return buf
+}
+
+// SetStacktrace captures the stack trace of entries at or above level, like
+// Lerror, for the logger and all loggers sharing its outputs. The empty level
+// disables stack traces.
+func (l *Logger) SetStacktrace(level string) {
+	if level == "" {
+		l.core.stack.Store(0)
+		return
+	}
+	l.core.stack.Store(int32(ltoi(level)) + 1)
+}
+
+// stackEnabled reports whether the stack trace of the severity n is
+// captured.
+func (l *Logger) stackEnabled(n int) bool {
+	s := int(l.core.stack.Load())
+	return s > 0 && n >= s-1
+}
@@ -0,0 +1,105 @@
+package mlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestCaller(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogger("test: ", &Settings{})
+	l.AddSink("buf", NewEncoderSink(&buf, Ldebug, &TextEncoder{Caller: CallerPackage, CallerFunc: true}))
+	l.Info("This is info")
+	if want, got := "github.com/ccpaging/mlog/caller_test.go:17 mlog.TestCaller: INFO test: This is info\n", buf.String(); want != got {
+		t.Errorf("caller should match %q is %q", want, got)
+	}
+
+	buf.Reset()
+	l.AddSink("buf", NewEncoderSink(&buf, Ldebug, &LogfmtEncoder{Caller: CallerModule}))
+	l.Info("This is info")
+	if want, got := " caller=caller_test.go:24 ", buf.String(); !strings.Contains(got, want) {
+		t.Errorf("caller should contain %q is %q", want, got)
+	}
+}
+
+func TestCallerModule(t *testing.T) {
+	dir := t.TempDir()
+	for path, content := range map[string]string{
+		"probe/go.mod":                 "// probe\nmodule example.com/probe\n\ngo 1.21\n",
+		"probe/cmd/server/main.go":     "package main\n",
+		"yaml/go.mod":                  "module \"gopkg.in/yaml.v3\"\n",
+		"yaml/yaml.go":                 "package yaml\n",
+		"probe/internal/db/db_test.go": "package db\n",
+	} {
+		path = filepath.Join(dir, path)
+		os.MkdirAll(filepath.Dir(path), 0700)
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	slash := filepath.ToSlash(dir)
+	for _, c := range []struct {
+		file, function string
+		module, pkg    string
+	}{
+		{slash + "/probe/cmd/server/main.go", "main.main", "cmd/server/main.go:12", "example.com/probe/cmd/server/main.go:12"},
+		{slash + "/yaml/yaml.go", "gopkg.in/yaml%2ev3.Marshal", "yaml.go:12", "gopkg.in/yaml.v3/yaml.go:12"},
+		{slash + "/probe/internal/db/db_test.go", "example.com/probe/internal/db.TestDB", "internal/db/db_test.go:12", "example.com/probe/internal/db/db_test.go:12"},
+	} {
+		frame := runtime.Frame{File: c.file, Function: c.function, Line: 12}
+		if got := formatCaller(frame, CallerModule, false); got != c.module {
+			t.Errorf("module caller of %s should be %q is %q", c.function, c.module, got)
+		}
+		if got := formatCaller(frame, CallerPackage, false); got != c.pkg {
+			t.Errorf("package caller of %s should be %q is %q", c.function, c.pkg, got)
+		}
+	}
+
+	// Built with -trimpath, or the source is not found.
+	defer func(path string) { ModulePath = path }(ModulePath)
+	ModulePath = "example.com/probe"
+	for _, c := range []struct {
+		file, function, want string
+	}{
+		{"example.com/probe/cmd/server/main.go", "main.main", "cmd/server/main.go:12"},
+		{"/gone/cmd/server/main.go", "main.main", "main.go:12"},
+		{"/gone/internal/db/db.go", "example.com/probe/internal/db.Open", "internal/db/db.go:12"},
+		{"/gone/yaml.go", "gopkg.in/yaml%2ev3.Marshal", "gopkg.in/yaml.v3/yaml.go:12"},
+	} {
+		frame := runtime.Frame{File: c.file, Function: c.function, Line: 12}
+		if got := formatCaller(frame, CallerModule, false); got != c.want {
+			t.Errorf("module caller of %s should be %q is %q", c.file, c.want, got)
+		}
+	}
+}
+
+func TestStacktrace(t *testing.T) {
+	var text, js bytes.Buffer
+	l := NewLogger("test: ", &Settings{StacktraceLevel: "error"})
+	l.AddSink("text", NewWriterSink(&text, Ldebug, 0))
+	l.AddSink("json", NewEncoderSink(&js, Ldebug, &JSONEncoder{}))
+
+	l.Warn("This is warn")
+	if strings.Contains(text.String(), "\t") {
+		t.Errorf("warn should not have stack trace, is %q", text.String())
+	}
+
+	text.Reset()
+	js.Reset()
+	l.Error("This is error")
+	if want, got := "EROR test: This is error\n\tgithub.com/ccpaging/mlog.TestStacktrace\n\t\t", text.String(); !strings.HasPrefix(got, want) {
+		t.Errorf("text stack trace should start with %q is %q", want, got)
+	}
+	var m struct{ Stack []string }
+	if err := json.Unmarshal(js.Bytes(), &m); err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Stack) == 0 || !strings.HasPrefix(m.Stack[0], "github.com/ccpaging/mlog.TestStacktrace ") {
+		t.Errorf("json stack trace is %q", m.Stack)
+	}
+}
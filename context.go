@@ -0,0 +1,129 @@
+package mlog
+
+import (
+	"context"
+	"sync"
+)
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the logger.
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the logger carried by ctx, or Default().
+func FromContext(ctx context.Context) *Logger {
+	if ctx != nil {
+		if l, ok := ctx.Value(contextKey{}).(*Logger); ok && l != nil {
+			return l
+		}
+	}
+	return Default()
+}
+
+type contextField struct {
+	name string
+	key  any
+}
+
+var contextFields struct {
+	mu     sync.RWMutex
+	fields []contextField
+}
+
+// RegisterContextKey adds the value of key in the context to every entry
+// logged with context as the field name, like trace ID, request ID.
+// Registering the same name again replaces the key.
+func RegisterContextKey(name string, key any) {
+	contextFields.mu.Lock()
+	defer contextFields.mu.Unlock()
+
+	for i := range contextFields.fields {
+		if contextFields.fields[i].name == name {
+			contextFields.fields[i].key = key
+			return
+		}
+	}
+	contextFields.fields = append(contextFields.fields, contextField{name, key})
+}
+
+// appendContext returns the fields of registered keys found in ctx,
+// followed by kv.
+func appendContext(ctx context.Context, kv []any) []any {
+	if ctx == nil {
+		return kv
+	}
+	contextFields.mu.RLock()
+	defer contextFields.mu.RUnlock()
+
+	var out []any
+	for _, f := range contextFields.fields {
+		if v := ctx.Value(f.key); v != nil {
+			out = append(out, Field{f.name, v})
+		}
+	}
+	if len(out) == 0 {
+		return kv
+	}
+	return append(out, kv...)
+}
+
+func (l *Logger) Loutputc(ctx context.Context, calldepth int, level string, msg string, kv ...any) {
+	if l.enabled(ltoi(level)) {
+		l.output(1+calldepth, level, msg, appendContext(ctx, kv))
+	}
+}
+
+func (l *Logger) DebugCtx(ctx context.Context, msg string, kv ...any) {
+	l.Loutputc(ctx, 1, Ldebug, msg, kv...)
+}
+
+func (l *Logger) TraceCtx(ctx context.Context, msg string, kv ...any) {
+	l.Loutputc(ctx, 1, Ltrace, msg, kv...)
+}
+
+func (l *Logger) InfoCtx(ctx context.Context, msg string, kv ...any) {
+	l.Loutputc(ctx, 1, Linfo, msg, kv...)
+}
+
+func (l *Logger) WarnCtx(ctx context.Context, msg string, kv ...any) {
+	l.Loutputc(ctx, 1, Lwarn, msg, kv...)
+}
+
+func (l *Logger) ErrorCtx(ctx context.Context, msg string, kv ...any) {
+	l.Loutputc(ctx, 1, Lerror, msg, kv...)
+}
+
+func (l *Logger) FatalCtx(ctx context.Context, msg string, kv ...any) {
+	l.Loutputc(ctx, 1, Lfatal, msg, kv...)
+	l.exit()
+}
+
+// The functions below log with the logger carried by ctx, or Default().
+
+func DebugCtx(ctx context.Context, msg string, kv ...any) {
+	FromContext(ctx).Loutputc(ctx, 1, Ldebug, msg, kv...)
+}
+
+func TraceCtx(ctx context.Context, msg string, kv ...any) {
+	FromContext(ctx).Loutputc(ctx, 1, Ltrace, msg, kv...)
+}
+
+func InfoCtx(ctx context.Context, msg string, kv ...any) {
+	FromContext(ctx).Loutputc(ctx, 1, Linfo, msg, kv...)
+}
+
+func WarnCtx(ctx context.Context, msg string, kv ...any) {
+	FromContext(ctx).Loutputc(ctx, 1, Lwarn, msg, kv...)
+}
+
+func ErrorCtx(ctx context.Context, msg string, kv ...any) {
+	FromContext(ctx).Loutputc(ctx, 1, Lerror, msg, kv...)
+}
+
+func FatalCtx(ctx context.Context, msg string, kv ...any) {
+	l := FromContext(ctx)
+	l.Loutputc(ctx, 1, Lfatal, msg, kv...)
+	l.exit()
+}
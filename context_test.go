@@ -0,0 +1,27 @@
+package mlog
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"testing"
+)
+
+type testCtxKey string
+
+func TestContext(t *testing.T) {
+	if FromContext(context.Background()) != Default() {
+		t.Errorf("logger from empty context should be default")
+	}
+
+	RegisterContextKey("request_id", testCtxKey("rid"))
+	var buf bytes.Buffer
+	l := New("ctx: ", log.New(&buf, "", 0), "")
+	ctx := context.WithValue(context.Background(), testCtxKey("rid"), "r-1")
+	ctx = NewContext(ctx, l)
+
+	InfoCtx(ctx, "This is info", "k", 1)
+	if want, got := "INFO ctx: This is info request_id=r-1 k=1\n", buf.String(); want != got {
+		t.Errorf("context output should match %q is %q", want, got)
+	}
+}
@@ -0,0 +1,303 @@
+package mlog
+
+import (
+	"encoding/json"
+	stdlog "log"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Encoder formats the entry as a line of output.
+type Encoder interface {
+	// Encode appends the entry and a newline to buf.
+	Encode(buf []byte, e *Entry) []byte
+}
+
+// newEncoder returns the encoder of the format name, like "json", writing
+// the caller in mode. The text encoder with std log flag is the default.
+func newEncoder(format string, flag int, caller Caller, callerFunc bool) Encoder {
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "json":
+		return &JSONEncoder{Caller: caller, CallerFunc: callerFunc}
+	case "logfmt":
+		return &LogfmtEncoder{Caller: caller, CallerFunc: callerFunc}
+	}
+	return &TextEncoder{Flag: flag, Caller: caller, CallerFunc: callerFunc}
+}
+
+// TextEncoder formats the entry as std log text line. The stack trace is
+// written as the block of lines following it.
+type TextEncoder struct {
+	Prefix     string
+	Flag       int    // std log flag, like LstdFlags
+	Caller     Caller // written after the time, if not CallerDefault
+	CallerFunc bool   // write the function name with caller
+}
+
+func (enc *TextEncoder) Encode(buf []byte, e *Entry) []byte {
+	file, line := "???", 0
+	if enc.Flag&(stdlog.Lshortfile|stdlog.Llongfile) != 0 && e.PC != 0 {
+		frame := e.Frame()
+		file, line = frame.File, frame.Line
+	}
+	formatHeader(&buf, e.Time, enc.Prefix, enc.Flag, file, line)
+	if enc.Caller != CallerDefault {
+		if caller := e.caller(enc.Caller, enc.CallerFunc); caller != "" {
+			buf = append(buf, caller...)
+			buf = append(buf, ": "...)
+		}
+	}
+	buf = append(buf, e.Level...)
+	buf = append(buf, e.Name...)
+	if len(e.Fields) == 0 {
+		buf = append(buf, e.Message...)
+		if len(e.Message) == 0 || e.Message[len(e.Message)-1] != '\n' {
+			buf = append(buf, '\n')
+		}
+		return appendStackText(buf, e)
+	}
+	buf = append(buf, strings.TrimRight(e.Message, "\r\n")...)
+	for _, f := range e.Fields {
+		buf = appendKeyValue(buf, f.Key, f.Value)
+	}
+	buf = append(buf, '\n')
+	return appendStackText(buf, e)
+}
+
+// JSONTimeFormat is the time layout of JSONEncoder.
+const JSONTimeFormat = "2006-01-02T15:04:05.000Z07:00"
+
+// JSONEncoder formats the entry as a JSON object per line, with the keys
+// "time", "level", "logger", "caller", "msg", the fields and "stack".
+type JSONEncoder struct {
+	Caller     Caller // CallerShort if CallerDefault
+	CallerFunc bool   // write the function name with caller
+}
+
+func (enc *JSONEncoder) Encode(buf []byte, e *Entry) []byte {
+	buf = append(buf, `{"time":"`...)
+	buf = e.Time.AppendFormat(buf, JSONTimeFormat)
+	buf = append(buf, `","level":`...)
+	buf = appendJSONString(buf, levelName(e.Level))
+	if name := trimName(e.Name); name != "" {
+		buf = append(buf, `,"logger":`...)
+		buf = appendJSONString(buf, name)
+	}
+	if caller := e.caller(defaultCaller(enc.Caller), enc.CallerFunc); caller != "" {
+		buf = append(buf, `,"caller":`...)
+		buf = appendJSONString(buf, caller)
+	}
+	buf = append(buf, `,"msg":`...)
+	buf = appendJSONString(buf, strings.TrimRight(e.Message, "\r\n"))
+	for _, f := range e.Fields {
+		buf = append(buf, ',')
+		buf = appendJSONString(buf, f.Key)
+		buf = append(buf, ':')
+		buf = appendJSONValue(buf, f.Value)
+	}
+	if len(e.Stack) > 0 {
+		buf = append(buf, `,"stack":[`...)
+		for i, line := range stackLines(e.Stack) {
+			if i > 0 {
+				buf = append(buf, ',')
+			}
+			buf = appendJSONString(buf, line)
+		}
+		buf = append(buf, ']')
+	}
+	return append(buf, "}\n"...)
+}
+
+// LogfmtEncoder formats the entry as a logfmt line, like
+//
+//	ts=2026-10-17T08:30:00.000Z level=info logger=root msg="hello world" key=value
+//
+// The stack trace is written as the key "stack" with the frames separated by
+// newlines.
+type LogfmtEncoder struct {
+	Caller     Caller // CallerShort if CallerDefault
+	CallerFunc bool   // write the function name with caller
+}
+
+func (enc *LogfmtEncoder) Encode(buf []byte, e *Entry) []byte {
+	buf = append(buf, "ts="...)
+	buf = e.Time.AppendFormat(buf, JSONTimeFormat)
+	buf = append(buf, " level="...)
+	buf = append(buf, levelName(e.Level)...)
+	if name := trimName(e.Name); name != "" {
+		buf = appendKeyValue(buf, "logger", name)
+	}
+	if caller := e.caller(defaultCaller(enc.Caller), enc.CallerFunc); caller != "" {
+		buf = appendKeyValue(buf, "caller", caller)
+	}
+	buf = appendKeyValue(buf, "msg", strings.TrimRight(e.Message, "\r\n"))
+	for _, f := range e.Fields {
+		buf = appendKeyValue(buf, logfmtKey(f.Key), f.Value)
+	}
+	if len(e.Stack) > 0 {
+		buf = appendKeyValue(buf, "stack", strings.Join(stackLines(e.Stack), "\n"))
+	}
+	return append(buf, '\n')
+}
+
+// defaultCaller returns CallerShort for CallerDefault.
+func defaultCaller(c Caller) Caller {
+	if c == CallerDefault {
+		return CallerShort
+	}
+	return c
+}
+
+// logfmtKey replaces the characters not allowed in logfmt key with '_'.
+func logfmtKey(key string) string {
+	if key == "" {
+		return "_"
+	}
+	if !needsQuoting(key) {
+		return key
+	}
+	return strings.Map(func(r rune) rune {
+		if r == '=' || r == '"' || r == '\\' || r == utf8.RuneError || unicode.IsSpace(r) || !unicode.IsPrint(r) {
+			return '_'
+		}
+		return r
+	}, key)
+}
+
+// appendJSONValue appends v as JSON. An error is written as its message,
+// and a value which can not be marshaled as its fmt.Sprint text.
+func appendJSONValue(buf []byte, v any) []byte {
+	switch v := v.(type) {
+	case string:
+		return appendJSONString(buf, v)
+	case error:
+		return appendJSONString(buf, errorString(v))
+	case time.Duration:
+		return appendJSONString(buf, v.String())
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return appendJSONString(buf, fieldString(v))
+	}
+	return append(buf, b...)
+}
+
+const hex = "0123456789abcdef"
+
+// appendJSONString appends s as quoted JSON string.
+func appendJSONString(buf []byte, s string) []byte {
+	buf = append(buf, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		b := s[i]
+		if b < utf8.RuneSelf {
+			if b >= 0x20 && b != '"' && b != '\\' {
+				i++
+				continue
+			}
+			buf = append(buf, s[start:i]...)
+			switch b {
+			case '"', '\\':
+				buf = append(buf, '\\', b)
+			case '\n':
+				buf = append(buf, '\\', 'n')
+			case '\r':
+				buf = append(buf, '\\', 'r')
+			case '\t':
+				buf = append(buf, '\\', 't')
+			default:
+				buf = append(buf, '\\', 'u', '0', '0', hex[b>>4], hex[b&0xf])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			buf = append(buf, s[start:i]...)
+			buf = append(buf, `\ufffd`...)
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	buf = append(buf, s[start:]...)
+	return append(buf, '"')
+}
+
+// Cheap integer to fixed-width decimal ASCII. Give a negative width to avoid zero-padding.
+func itoa(buf *[]byte, i int, wid int) {
+	// Assemble decimal in reverse order.
+	var b [20]byte
+	bp := len(b) - 1
+	for i >= 10 || wid > 1 {
+		wid--
+		q := i / 10
+		b[bp] = byte('0' + i - q*10)
+		bp--
+		i = q
+	}
+	// i < 10
+	b[bp] = byte('0' + i)
+	*buf = append(*buf, b[bp:]...)
+}
+
+// formatHeader writes log header to buf in following order:
+//   - prefix (if it's not blank and Lmsgprefix is unset),
+//   - date and/or time (if corresponding flags are provided),
+//   - file and line number (if corresponding flags are provided),
+//   - prefix (if it's not blank and Lmsgprefix is set).
+func formatHeader(buf *[]byte, t time.Time, prefix string, flag int, file string, line int) {
+	if flag&stdlog.Lmsgprefix == 0 {
+		*buf = append(*buf, prefix...)
+	}
+	if flag&(stdlog.Ldate|stdlog.Ltime|stdlog.Lmicroseconds) != 0 {
+		if flag&stdlog.LUTC != 0 {
+			t = t.UTC()
+		}
+		if flag&stdlog.Ldate != 0 {
+			year, month, day := t.Date()
+			itoa(buf, year, 4)
+			*buf = append(*buf, '/')
+			itoa(buf, int(month), 2)
+			*buf = append(*buf, '/')
+			itoa(buf, day, 2)
+			*buf = append(*buf, ' ')
+		}
+		if flag&(stdlog.Ltime|stdlog.Lmicroseconds) != 0 {
+			hour, min, sec := t.Clock()
+			itoa(buf, hour, 2)
+			*buf = append(*buf, ':')
+			itoa(buf, min, 2)
+			*buf = append(*buf, ':')
+			itoa(buf, sec, 2)
+			if flag&stdlog.Lmicroseconds != 0 {
+				*buf = append(*buf, '.')
+				itoa(buf, t.Nanosecond()/1e3, 6)
+			}
+			*buf = append(*buf, ' ')
+		}
+	}
+	if flag&(stdlog.Lshortfile|stdlog.Llongfile) != 0 {
+		if flag&stdlog.Lshortfile != 0 {
+			short := file
+			for i := len(file) - 1; i > 0; i-- {
+				if file[i] == '/' {
+					short = file[i+1:]
+					break
+				}
+			}
+			file = short
+		}
+		*buf = append(*buf, file...)
+		*buf = append(*buf, ':')
+		itoa(buf, line, -1)
+		*buf = append(*buf, ": "...)
+	}
+	if flag&stdlog.Lmsgprefix != 0 {
+		*buf = append(*buf, prefix...)
+	}
+}
@@ -0,0 +1,53 @@
+package mlog
+
+import (
+	"encoding/json"
+	"errors"
+	stdlog "log"
+	"testing"
+	"time"
+)
+
+var testEntry = Entry{
+	Time:    time.Date(2026, 10, 17, 8, 30, 0, 0, time.UTC),
+	Level:   Lwarn,
+	Name:    "main: ",
+	Message: "disk \"/\" is full\n",
+	Fields:  []Field{{"used", 0.97}, {"err", errors.New("no space")}},
+}
+
+func TestTextEncoder(t *testing.T) {
+	enc := &TextEncoder{Flag: LstdFlags | stdlog.LUTC}
+	got := string(enc.Encode(nil, &testEntry))
+	if want := "2026/10/17 08:30:00 WARN main: disk \"/\" is full used=0.97 err=\"no space\"\n"; want != got {
+		t.Errorf("text should match %q is %q", want, got)
+	}
+}
+
+func TestJSONEncoder(t *testing.T) {
+	enc := &JSONEncoder{}
+	got := string(enc.Encode(nil, &testEntry))
+	if want := `{"time":"2026-10-17T08:30:00.000Z","level":"warn","logger":"main","msg":"disk \"/\" is full","used":0.97,"err":"no space"}` + "\n"; want != got {
+		t.Errorf("json should match %q is %q", want, got)
+	}
+
+	e := testEntry
+	e.Message = "bad \x01 \xff utf8"
+	var m map[string]any
+	if err := json.Unmarshal(enc.Encode(nil, &e), &m); err != nil {
+		t.Fatal(err)
+	}
+	if want, got := "bad \x01 � utf8", m["msg"]; want != got {
+		t.Errorf("json msg should match %q is %q", want, got)
+	}
+}
+
+func TestLogfmtEncoder(t *testing.T) {
+	enc := &LogfmtEncoder{}
+	e := testEntry
+	e.Fields = append(e.Fields, Field{"bad key=", "tab\there"})
+	got := string(enc.Encode(nil, &e))
+	if want := `ts=2026-10-17T08:30:00.000Z level=warn logger=main msg="disk \"/\" is full" used=0.97 err="no space" bad_key_="tab\there"` + "\n"; want != got {
+		t.Errorf("logfmt should match %q is %q", want, got)
+	}
+}
@@ -0,0 +1,129 @@
+package mlog
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// maxErrorDepth limits walking the tree of wrapped errors.
+const maxErrorDepth = 32
+
+// errorValue is the value of the field returned by Err, which is expanded
+// to the fields of the error.
+type errorValue struct {
+	err error
+}
+
+// Err returns the field of err with the key "error". It is expanded to the
+// fields "error.message", "error.type", and if any, "error.chain" of the
+// wrapped and joined errors, and "error.stack" of the first StackTracer.
+func Err(err error) Field {
+	return NamedErr("error", err)
+}
+
+// NamedErr returns the field of err like Err, with the key.
+func NamedErr(key string, err error) Field {
+	return Field{Key: key, Value: errorValue{err}}
+}
+
+// errorChain is the type and message of errors, in depth-first order of
+// errors.Unwrap and errors.Join.
+type errorChain []string
+
+func (c errorChain) String() string {
+	return strings.Join(c, "; ")
+}
+
+// stackTrace is the stack trace of an error, one "function file:line" per
+// line.
+type stackTrace []string
+
+func (s stackTrace) String() string {
+	return strings.Join(s, "\n")
+}
+
+// appendError appends the fields of err with the key prefix to fs.
+func appendError(fs []Field, key string, err error) []Field {
+	if err == nil {
+		return append(fs, Field{key, nil})
+	}
+	fs = append(fs,
+		Field{key + ".message", errorString(err)},
+		Field{key + ".type", fmt.Sprintf("%T", err)},
+	)
+	var chain errorChain
+	var stack []uintptr
+	walkError(err, 0, func(e error) {
+		chain = append(chain, fmt.Sprintf("%T: %s", e, errorString(e)))
+		if stack == nil {
+			stack = errorStack(e)
+		}
+	})
+	if len(chain) > 1 {
+		fs = append(fs, Field{key + ".chain", chain})
+	}
+	if len(stack) > 0 {
+		fs = append(fs, Field{key + ".stack", stackTrace(stackLines(stack))})
+	}
+	return fs
+}
+
+// walkError calls fn for err and the errors wrapped or joined by it. The
+// walk stops at an Unwrap method panicking.
+func walkError(err error, depth int, fn func(error)) {
+	if err == nil || depth >= maxErrorDepth {
+		return
+	}
+	fn(err)
+	defer func() { recover() }()
+	switch x := err.(type) {
+	case interface{ Unwrap() []error }:
+		for _, e := range x.Unwrap() {
+			walkError(e, depth+1, fn)
+		}
+	default:
+		walkError(errors.Unwrap(err), depth+1, fn)
+	}
+}
+
+// StackTracer is implemented by errors exposing the program counters of the
+// stack trace where they were created, like by runtime.Callers.
+type StackTracer interface {
+	StackTrace() []uintptr
+}
+
+// errorStack returns the program counters of the stack trace exposed by err,
+// if it is a StackTracer.
+func errorStack(err error) (pcs []uintptr) {
+	st, ok := err.(StackTracer)
+	if !ok {
+		return nil
+	}
+	defer func() {
+		if recover() != nil {
+			pcs = nil
+		}
+	}()
+	return st.StackTrace()
+}
+
+// errorString returns err.Error(). If it panics, like for a nil pointer of
+// an error type, "<nil>" or the panic is returned as fmt does.
+func errorString(err error) (s string) {
+	defer func() {
+		if p := recover(); p != nil {
+			if v := reflect.ValueOf(err); v.Kind() == reflect.Pointer && v.IsNil() {
+				s = "<nil>"
+				return
+			}
+			s = fmt.Sprintf("%%!v(PANIC=Error method: %v)", p)
+		}
+	}()
+	return err.Error()
+}
+
+func (l *Logger) ErrorErr(err error, msg string, kv ...any) {
+	l.Loutputw(1, Lerror, msg, append([]any{Err(err)}, kv...)...)
+}
@@ -0,0 +1,107 @@
+package mlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+type stackError struct {
+	msg string
+	pcs []uintptr
+}
+
+func (e *stackError) Error() string         { return e.msg }
+func (e *stackError) StackTrace() []uintptr { return e.pcs }
+
+func newStackError(msg string) error {
+	pcs := make([]uintptr, 8)
+	return &stackError{msg, pcs[:runtime.Callers(1, pcs)]}
+}
+
+func TestErrorErr(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogger("test: ", &Settings{})
+	l.AddSink("json", NewEncoderSink(&buf, Ldebug, &JSONEncoder{Caller: CallerNone}))
+
+	err := fmt.Errorf("load config: %w", errors.Join(fs.ErrNotExist, newStackError("bad disk")))
+	l.ErrorErr(err, "This is error", "k", 1)
+
+	var m map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+		t.Fatal(err)
+	}
+	if want, got := "load config: file does not exist\nbad disk", m["error.message"]; want != got {
+		t.Errorf("error.message should be %q is %q", want, got)
+	}
+	if want, got := "*fmt.wrapError", m["error.type"]; want != got {
+		t.Errorf("error.type should be %q is %q", want, got)
+	}
+	chain, _ := m["error.chain"].([]any)
+	if len(chain) != 4 || chain[2] != "*errors.errorString: file does not exist" || chain[3] != "*mlog.stackError: bad disk" {
+		t.Errorf("error.chain is %q", chain)
+	}
+	stack, _ := m["error.stack"].([]any)
+	if len(stack) == 0 || !strings.HasPrefix(stack[0].(string), "github.com/ccpaging/mlog.newStackError ") {
+		t.Errorf("error.stack is %q", stack)
+	}
+	if m["k"] != 1.0 {
+		t.Errorf("fields should follow error, are %v", m)
+	}
+
+	buf.Reset()
+	l.AddSink("json", NewWriterSink(&buf, Ldebug, 0))
+	l.Errorw("This is error", Err(errors.New("plain")))
+	if want, got := "EROR test: This is error error.message=plain error.type=*errors.errorString\n", buf.String(); want != got {
+		t.Errorf("text should match %q is %q", want, got)
+	}
+}
+
+type ptrError struct {
+	msg string
+}
+
+func (e *ptrError) Error() string { return e.msg }
+
+type panicError struct{}
+
+func (panicError) Error() string { panic("boom") }
+
+// callersError exposes the stack trace by a method other than StackTrace.
+type callersError struct{}
+
+func (callersError) Error() string      { return "callers" }
+func (callersError) Callers() []uintptr { return []uintptr{1, 2} }
+
+func TestErrPanicking(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLogger("test: ", &Settings{})
+	l.AddSink("json", NewEncoderSink(&buf, Ldebug, &JSONEncoder{Caller: CallerNone}))
+
+	for _, c := range []struct {
+		err  error
+		want string
+	}{
+		{(*ptrError)(nil), "<nil>"},
+		{panicError{}, "%!v(PANIC=Error method: boom)"},
+		{fmt.Errorf("wrap: %w", callersError{}), "wrap: callers"},
+	} {
+		buf.Reset()
+		l.ErrorErr(c.err, "This is error", "e", c.err)
+		var m map[string]any
+		if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+			t.Fatal(err)
+		}
+		if got := m["error.message"]; got != c.want {
+			t.Errorf("error.message should be %q is %q", c.want, got)
+		}
+		if _, ok := m["error.stack"]; ok {
+			t.Errorf("error.stack should be of StackTracer only, is %q", m["error.stack"])
+		}
+	}
+}
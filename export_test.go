@@ -0,0 +1,12 @@
+package mlog
+
+// ResetRegistry forgets the named loggers and the levels set by name, so
+// the registry tests can run more than once.
+func ResetRegistry() {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	registry.loggers = map[string]*Logger{"": global}
+	registry.levels = make(map[string]int)
+	global.level.Store(-1)
+}
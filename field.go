@@ -0,0 +1,93 @@
+package mlog
+
+import (
+	"fmt"
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+)
+
+const badKey = "!BADKEY"
+
+// Field is a key/value pair attached to the entry.
+type Field struct {
+	Key   string
+	Value any
+}
+
+// Any returns a field with the key and value.
+func Any(key string, value any) Field {
+	return Field{Key: key, Value: value}
+}
+
+// appendFields converts the alternating keys and values in kv to fields,
+// and appends them to fs. A Field in kv is used as it is, except Err which
+// is expanded. A value without a string key gets the key "!BADKEY".
+func appendFields(fs []Field, kv []any) []Field {
+	for len(kv) > 0 {
+		switch k := kv[0].(type) {
+		case Field:
+			if ev, ok := k.Value.(errorValue); ok {
+				fs = appendError(fs, k.Key, ev.err)
+			} else {
+				fs = append(fs, k)
+			}
+			kv = kv[1:]
+		case string:
+			if len(kv) == 1 {
+				fs = append(fs, Field{badKey, k})
+				kv = nil
+			} else {
+				fs = append(fs, Field{k, kv[1]})
+				kv = kv[2:]
+			}
+		default:
+			fs = append(fs, Field{badKey, k})
+			kv = kv[1:]
+		}
+	}
+	return fs
+}
+
+// fieldString returns the value as the text shown in the log line.
+func fieldString(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return fmt.Sprint(v)
+}
+
+// needsQuoting reports whether s must be quoted in key=value text.
+func needsQuoting(s string) bool {
+	if len(s) == 0 {
+		return true
+	}
+	for i := 0; i < len(s); {
+		b := s[i]
+		if b < utf8.RuneSelf {
+			if b <= ' ' || b == '=' || b == '"' || b == '\\' || b == 0x7f {
+				return true
+			}
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError || unicode.IsSpace(r) || !unicode.IsPrint(r) {
+			return true
+		}
+		i += size
+	}
+	return false
+}
+
+// appendKeyValue appends " key=value" to buf, quoting value if needed.
+func appendKeyValue(buf []byte, key string, value any) []byte {
+	buf = append(buf, ' ')
+	buf = append(buf, key...)
+	buf = append(buf, '=')
+	s := fieldString(value)
+	if needsQuoting(s) {
+		return strconv.AppendQuote(buf, s)
+	}
+	return append(buf, s...)
+}
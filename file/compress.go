@@ -0,0 +1,200 @@
+package file
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Compressor compresses the rolled files.
+type Compressor interface {
+	// Ext returns the extension appended to the compressed files, like ".gz".
+	Ext() string
+	// Compress writes the compressed data of src to dst.
+	Compress(dst io.Writer, src io.Reader) error
+}
+
+// Gzip compresses the rolled files to ".gz" files.
+var Gzip Compressor = gzipCompressor{gzip.DefaultCompression}
+
+type gzipCompressor struct {
+	level int
+}
+
+func (gzipCompressor) Ext() string { return ".gz" }
+
+func (c gzipCompressor) Compress(dst io.Writer, src io.Reader) error {
+	zw, err := gzip.NewWriterLevel(dst, c.level)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(zw, src); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// compressExt returns the extension of the compressed files, ".gz" if no
+// compressor, so the files compressed before are still found.
+func (f *File) compressExt() string {
+	if f.Compress != nil {
+		return f.Compress.Ext()
+	}
+	return ".gz"
+}
+
+// backupExists reports whether the rolled file at path exists, compressed
+// or not.
+func (f *File) backupExists(path string) bool {
+	return exists(path) || exists(path+f.compressExt())
+}
+
+// renameBackup renames the rolled file, compressed or not.
+func (f *File) renameBackup(from, to string) {
+	for _, suffix := range []string{"", f.compressExt()} {
+		if exists(from + suffix) {
+			os.Rename(from+suffix, to+suffix)
+		}
+	}
+	f.moveJob(from, to)
+}
+
+// removeBackup removes the rolled file, compressed or not.
+func (f *File) removeBackup(path string) {
+	os.Remove(path)
+	os.Remove(path + f.compressExt())
+	f.moveJob(path, "")
+}
+
+// compressJob is a rolled file compressed in background. Its path follows
+// the file renamed by rolling, and is "" once the file is removed.
+type compressJob struct {
+	path string
+}
+
+// moveJob updates the path of the compression of the rolled file renamed
+// from to, or removed if to is "".
+func (f *File) moveJob(from, to string) {
+	for _, job := range f.jobs {
+		if job.path == from {
+			job.path = to
+		}
+	}
+}
+
+// rolled compresses the file rolled to path in background if f.Compress is
+// set, and then applies the retention.
+func (f *File) rolled(path string) {
+	if f.Compress == nil {
+		f.retain()
+		return
+	}
+	f.compress(path)
+}
+
+// compress compresses the rolled file at path in background. The compressed
+// data is written to a temporary file first, which is renamed when complete,
+// so the file is never removed unless its compressed file is complete. The
+// rolled file may be renamed by rolling meanwhile, f.mu is held only to
+// complete. The file being compressed is skipped.
+func (f *File) compress(path string) {
+	for _, job := range f.jobs {
+		if job.path == path {
+			return
+		}
+	}
+	c := f.Compress
+	src, err := os.Open(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Compress file", err)
+		return
+	}
+	job := &compressJob{path}
+	f.jobs = append(f.jobs, job)
+	mode := f.FileMode
+	f.compressing.Add(1)
+	go func() {
+		defer f.compressing.Done()
+		tmp, err := compressTemp(c, src, path+c.Ext(), mode)
+
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		for i := range f.jobs {
+			if f.jobs[i] == job {
+				f.jobs = append(f.jobs[:i], f.jobs[i+1:]...)
+				break
+			}
+		}
+		if err == nil && job.path != "" {
+			if err = os.Rename(tmp, job.path+c.Ext()); err == nil {
+				err = os.Remove(job.path)
+			}
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Compress file", err)
+		}
+		// left if not renamed
+		os.Remove(tmp)
+		f.retain()
+	}()
+}
+
+// compressTemp writes the file src compressed by c to a temporary file next
+// to dst, and closes src. The temporary file is removed if it fails.
+func compressTemp(c Compressor, src *os.File, dst string, mode os.FileMode) (tmp string, err error) {
+	defer src.Close()
+
+	out, err := os.CreateTemp(filepath.Dir(dst), filepath.Base(dst)+".*.tmp")
+	if err != nil {
+		return "", err
+	}
+	tmp = out.Name()
+	defer func() {
+		if err != nil {
+			out.Close()
+			os.Remove(tmp)
+		}
+	}()
+	if err = out.Chmod(mode); err != nil {
+		return tmp, err
+	}
+	if err = c.Compress(out, src); err != nil {
+		return tmp, err
+	}
+	if err = out.Sync(); err != nil {
+		return tmp, err
+	}
+	if err = out.Close(); err != nil {
+		return tmp, err
+	}
+	// The compressed file is as old as the file, for retention.
+	if fi, err := src.Stat(); err == nil {
+		os.Chtimes(tmp, fi.ModTime(), fi.ModTime())
+	}
+	return tmp, nil
+}
+
+// resume removes the temporary files left by the compressions interrupted,
+// and compresses the backups left uncompressed, if f.Compress is set.
+func (f *File) resume() {
+	if f.Compress == nil {
+		return
+	}
+	name, ext := f.splitPath()
+	dir, prefix := filepath.Split(name)
+	entries, _ := os.ReadDir(filepath.Dir(f.FilePath))
+	for _, e := range entries {
+		n := e.Name()
+		if strings.HasPrefix(n, prefix+".") && strings.HasSuffix(n, ".tmp") && strings.Contains(n, ext+f.compressExt()+".") {
+			os.Remove(dir + n)
+		}
+	}
+	for _, r := range f.rolledFiles() {
+		if isBackup(r.middle) && !strings.HasSuffix(r.path, f.compressExt()) {
+			f.compress(r.path)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package file
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func gunzip(t *testing.T, path string) string {
+	t.Helper()
+	r, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestCompressRolling(t *testing.T) {
+	dir := t.TempDir()
+	f, err := OpenFile(filepath.Join(dir, "app.log"), 10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Compress = Gzip
+	for _, s := range []string{"first line\n", "second line\n", "third line\n", "fourth line\n"} {
+		f.Write([]byte(s))
+	}
+	f.Close()
+
+	if want, got := []string{"app.1.log.gz", "app.2.log.gz", "app.log"}, files(t, dir); !equal(want, got) {
+		t.Fatalf("files should be %v, are %v", want, got)
+	}
+	if got := gunzip(t, filepath.Join(dir, "app.1.log.gz")); got != "third line\n" {
+		t.Errorf("app.1.log.gz should have third line, has %q", got)
+	}
+	if got := gunzip(t, filepath.Join(dir, "app.2.log.gz")); got != "second line\n" {
+		t.Errorf("app.2.log.gz should have second line, has %q", got)
+	}
+}
+
+func TestCompressRotation(t *testing.T) {
+	dir := t.TempDir()
+	c := &clock{time.Date(2026, 10, 17, 8, 0, 0, 0, time.UTC)}
+	f, err := OpenFile(filepath.Join(dir, "app.log"), 0, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.LimitSize = 10
+	f.Rotation = RotateDaily
+	f.Compress = Gzip
+	f.Now = c.Now
+
+	for i := 0; i < 3; i++ {
+		f.Write([]byte("0123456789ab\n"))
+	}
+	f.Close()
+
+	want := []string{"app.2026-10-17.1.log.gz", "app.2026-10-17.log.gz", "app.log"}
+	if got := files(t, dir); !equal(want, got) {
+		t.Errorf("files should be %v, are %v", want, got)
+	}
+}
+
+// failCompressor writes some data, and fails.
+type failCompressor struct{}
+
+func (failCompressor) Ext() string { return ".gz" }
+
+func (failCompressor) Compress(dst io.Writer, src io.Reader) error {
+	io.CopyN(dst, src, 4)
+	return errors.New("disk full")
+}
+
+func TestCompressFailure(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.1.log")
+	if err := os.WriteFile(path, []byte("keep me\n"), 0660); err != nil {
+		t.Fatal(err)
+	}
+	f, err := Open(filepath.Join(dir, "app.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Compress = failCompressor{}
+	f.mu.Lock()
+	f.compress(path)
+	f.mu.Unlock()
+	f.Close()
+
+	if want, got := []string{"app.1.log"}, files(t, dir); !equal(want, got) {
+		t.Errorf("files should be %v, are %v", want, got)
+	}
+	if b, _ := os.ReadFile(path); string(b) != "keep me\n" {
+		t.Errorf("file should be kept, has %q", b)
+	}
+}
+
+func TestCompressResume(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"app.1.log":        "first\n",
+		"app.2.log.gz.tmp": "partial",
+		"app.2.log":        "second\n",
+		"app.x.log.tmp":    "not ours",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0660); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f, err := Open(filepath.Join(dir, "app.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Compress = Gzip
+	f.BackupFiles = 2
+	f.Write([]byte("third\n"))
+	f.Close()
+
+	if want, got := []string{"app.1.log.gz", "app.2.log.gz", "app.log", "app.x.log.tmp"}, files(t, dir); !equal(want, got) {
+		t.Fatalf("files should be %v, are %v", want, got)
+	}
+	if got := gunzip(t, filepath.Join(dir, "app.2.log.gz")); got != "second\n" {
+		t.Errorf("app.2.log.gz should have second, has %q", got)
+	}
+}
+
+// blockCompressor compresses by Gzip once release is closed.
+type blockCompressor struct {
+	release chan struct{}
+}
+
+func (blockCompressor) Ext() string { return ".gz" }
+
+func (c blockCompressor) Compress(dst io.Writer, src io.Reader) error {
+	<-c.release
+	return Gzip.Compress(dst, src)
+}
+
+func TestCompressRollingMeanwhile(t *testing.T) {
+	dir := t.TempDir()
+	f, err := OpenFile(filepath.Join(dir, "app.log"), 10, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := blockCompressor{make(chan struct{})}
+	f.Compress = c
+	// The compressions are blocked, rolling goes on renaming the files.
+	for _, s := range []string{"first line\n", "second line\n", "third line\n", "fourth line\n"} {
+		f.Write([]byte(s))
+	}
+	close(c.release)
+	f.Close()
+
+	if want, got := []string{"app.1.log.gz", "app.2.log.gz", "app.3.log.gz", "app.log"}, files(t, dir); !equal(want, got) {
+		t.Fatalf("files should be %v, are %v", want, got)
+	}
+	for i, want := range []string{"third line\n", "second line\n", "first line\n"} {
+		if got := gunzip(t, filepath.Join(dir, "app."+strconv.Itoa(i+1)+".log.gz")); got != want {
+			t.Errorf("app.%d.log.gz should have %q, has %q", i+1, want, got)
+		}
+	}
+}
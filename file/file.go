@@ -1,7 +1,8 @@
 // Copyright (C) 2021, ccpaging <ccpaging@gmail.com>.  All rights reserved.
 
 // Package rollfile provides a file writer with buffering, rolling up automatic,
-// thread safe(using sync lock) functionality.
+// thread safe(using sync lock) functionality. The buffered data is flushed
+// every FlushInterval in background.
 //
 // Here is a simple example, opening a file and writing some of it.
 //
@@ -35,6 +36,8 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"sync"
+	"time"
 )
 
 var (
@@ -55,9 +58,14 @@ var (
 	DefaultLimitSize int64 = 1024 * 1024
 
 	DefaultBufferSize = 2 * os.Getpagesize()
+
+	DefaultFlushInterval = time.Second
+
+	DefaultCheckInterval = time.Second
 )
 
-// File represents the buffered writer, and rolling up automatic.
+// File represents the buffered writer, and rolling up automatic. It is safe
+// for concurrent use. The exported fields must be set before writing.
 type File struct {
 	FilePath    string
 	FileMode    os.FileMode
@@ -69,6 +77,45 @@ type File struct {
 
 	bufWriter  *bufio.Writer
 	Buffersize int
+
+	// Rotation rolls the file when its period ends, to "name.<period>.ext"
+	// like "app.2026-10-17.log", or with ".1", ".2" suffixes like
+	// "app.2026-10-17.1.log" if LimitSize is reached in the period too.
+	// LimitSize 0 rolls by time only. BackupFiles limits the files rolled.
+	Rotation Rotation
+	// Now returns the current time for Rotation, time.Now if nil.
+	Now func() time.Time
+
+	// Compress compresses the rolled files in background, like Gzip, to
+	// "name.1.ext.gz". The rolled file is removed only when compressed. The
+	// compressions interrupted are resumed at open.
+	Compress Compressor
+
+	// MaxAge removes the rolled files modified before it, and MaxTotalSize
+	// removes the oldest rolled files beyond it in total, after rolling and
+	// at open. Zero means no limit.
+	MaxAge       time.Duration
+	MaxTotalSize int64
+
+	// FlushInterval is the interval of flushing the buffered data in
+	// background, while the file is open. Zero disables it.
+	FlushInterval time.Duration
+	// CheckInterval is the interval of checking in background, while the
+	// file is open, whether the file path is removed, points to another
+	// file, or the file is truncated, like by logrotate. The file is
+	// reopened if so. Zero disables it.
+	CheckInterval time.Duration
+
+	mu          sync.Mutex
+	bgStop      chan struct{}  // stops the background goroutine, nil if not running
+	bgDone      chan struct{}  // closed when the background goroutine returns
+	sig         chan os.Signal // signals reopening the file, nil if none
+	sigDone     chan struct{}  // closed when the signal goroutine returns
+	opened      bool           // opened once, retention applied
+	closed      bool           // closed by Close, no background goroutine
+	period      time.Time      // start of the period of the file, for Rotation
+	compressing sync.WaitGroup // compressions in background
+	jobs        []*compressJob // compressions in background, by path
 }
 
 // Open opens the named file for writing. If successful, methods on
@@ -103,6 +150,9 @@ func OpenFile(filePath string, limitSize int64, backupFiles int) (*File, error)
 		LimitSize:   limitSize,
 		BackupFiles: backupFiles,
 		Buffersize:  DefaultBufferSize,
+
+		FlushInterval: DefaultFlushInterval,
+		CheckInterval: DefaultCheckInterval,
 	}
 	f.size = f.fileSize()
 	return f, nil
@@ -123,9 +173,24 @@ func (f *File) close() (err error) {
 	return
 }
 
-// Close active buffered writer.
+// Close active buffered writer, and waits for the compressions. The file
+// may be written after Close, without flushing in background.
 func (f *File) Close() error {
-	return f.close()
+	f.mu.Lock()
+	f.closed = true
+	f.mu.Unlock()
+
+	// The signal goroutine may reopen the file, so it is stopped first.
+	f.stopSignal()
+	f.stopBackground()
+
+	f.mu.Lock()
+	err := f.close()
+	f.mu.Unlock()
+
+	// The compressions complete with f.mu held.
+	f.compressing.Wait()
+	return err
 }
 
 func (f *File) open() error {
@@ -147,6 +212,16 @@ func (f *File) open() error {
 	if fi, err := f.file.Stat(); err == nil {
 		f.size = fi.Size()
 	}
+	if !f.opened {
+		f.opened = true
+		f.resume()
+		f.retain()
+	}
+	if (f.FlushInterval > 0 || f.CheckInterval > 0) && f.bgStop == nil && !f.closed {
+		f.bgStop = make(chan struct{})
+		f.bgDone = make(chan struct{})
+		go f.background(f.FlushInterval, f.CheckInterval, f.bgStop, f.bgDone)
+	}
 	return nil
 }
 
@@ -165,7 +240,12 @@ func (f *File) write(b []byte) (n int, err error) {
 
 // Write bytes to file, and rolling up automatic.
 func (f *File) Write(b []byte) (n int, err error) {
-	if f.LimitSize > 0 && f.size > f.LimitSize {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.Rotation != RotateNone {
+		f.rotate()
+	} else if f.LimitSize > 0 && f.size > f.LimitSize {
 		f.rolling(f.BackupFiles)
 	}
 
@@ -185,36 +265,34 @@ func (f *File) rolling(n int) {
 		return
 	}
 
-	ext := filepath.Ext(f.FilePath)                  // save extension like ".log"
-	name := f.FilePath[0 : len(f.FilePath)-len(ext)] // dir and name
+	name, ext := f.splitPath() // dir and name, extension like ".log"
 
 	var (
 		i    int
-		err  error
 		slot string
 	)
 
 	for i = 0; i < n; i++ {
 		// File name pattern is "name.<n>.ext"
 		slot = name + "." + strconv.Itoa(i+1) + ext
-		_, err = os.Stat(slot)
-		if err != nil {
+		if !f.backupExists(slot) {
 			break
 		}
 	}
-	if err == nil {
+	if i == n {
 		// Too much backup files. Remove last one
-		os.Remove(slot)
+		f.removeBackup(slot)
 		i--
 	}
 
 	for ; i > 0; i-- {
 		prev := name + "." + strconv.Itoa(i) + ext
-		os.Rename(prev, slot)
+		f.renameBackup(prev, slot)
 		slot = prev
 	}
 
-	os.Rename(f.FilePath, name+".1"+ext)
+	os.Rename(f.FilePath, slot)
+	f.rolled(slot)
 }
 
 func (f *File) flush() {
@@ -227,6 +305,79 @@ func (f *File) flush() {
 	}
 }
 
+// Flush writes the buffered data to the file.
+func (f *File) Flush() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.bufWriter != nil {
+		return f.bufWriter.Flush()
+	}
+	return nil
+}
+
+// Sync writes the buffered data to the file, and commits the file to
+// stable storage, so it is not lost if the process is killed.
+func (f *File) Sync() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.bufWriter != nil {
+		if err := f.bufWriter.Flush(); err != nil {
+			return err
+		}
+	}
+	if f.file != nil {
+		return f.file.Sync()
+	}
+	return nil
+}
+
+// background flushes the file every flush interval, and checks it every
+// check interval, until stop is closed.
+func (f *File) background(flush, check time.Duration, stop, done chan struct{}) {
+	defer close(done)
+
+	var flushTick, checkTick <-chan time.Time
+	if flush > 0 {
+		ticker := time.NewTicker(flush)
+		defer ticker.Stop()
+		flushTick = ticker.C
+	}
+	if check > 0 {
+		ticker := time.NewTicker(check)
+		defer ticker.Stop()
+		checkTick = ticker.C
+	}
+	for {
+		select {
+		case <-stop:
+			return
+		case <-flushTick:
+			f.mu.Lock()
+			f.flush()
+			f.mu.Unlock()
+		case <-checkTick:
+			f.mu.Lock()
+			f.check()
+			f.mu.Unlock()
+		}
+	}
+}
+
+// stopBackground stops the background goroutine, if running.
+func (f *File) stopBackground() {
+	f.mu.Lock()
+	stop, done := f.bgStop, f.bgDone
+	f.bgStop, f.bgDone = nil, nil
+	f.mu.Unlock()
+
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+}
+
 func (f *File) fileSize() int64 {
 	if f.file != nil {
 		return f.size
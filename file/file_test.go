@@ -5,8 +5,12 @@ package file
 import (
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"runtime"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 var testFiles []string = []string{"_test.log", "_test.1.log"}
@@ -86,3 +90,107 @@ func BenchmarkBuffered(b *testing.B) {
 	}
 	b.StopTimer()
 }
+
+func TestConcurrentWrite(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	f, err := OpenFile(path, 1024, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				f.Write([]byte(testLongString + "\n"))
+			}
+		}()
+	}
+	wg.Wait()
+	f.Close()
+
+	lines := 0
+	for _, name := range files(t, dir) {
+		b, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines += strings.Count(string(b), testLongString+"\n")
+	}
+	if lines != 800 {
+		t.Errorf("files should have 800 lines, have %d", lines)
+	}
+}
+
+func TestFlushInterval(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	f, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	f.FlushInterval = 10 * time.Millisecond
+
+	f.Write([]byte(testString))
+	for i := 0; i < 100; i++ {
+		if b, _ := os.ReadFile(path); string(b) == testString {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Errorf("buffered data should be flushed in background")
+}
+
+func TestSync(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	f, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	f.FlushInterval = 0
+
+	f.Write([]byte(testString))
+	if b, _ := os.ReadFile(path); len(b) != 0 {
+		t.Errorf("data should be buffered, file has %q", b)
+	}
+	if err := f.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := os.ReadFile(path); string(b) != testString {
+		t.Errorf("data should be synced, file has %q", b)
+	}
+}
+
+func TestCloseStopsBackground(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	f, err := OpenFile(path, 64, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.FlushInterval = time.Millisecond
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 200; i++ {
+			f.Write([]byte(testLongString + "\n"))
+		}
+	}()
+	f.Write([]byte(testString))
+	f.Close()
+	wg.Wait()
+	f.Write([]byte(testString))
+
+	f.mu.Lock()
+	running := f.bgStop != nil
+	f.mu.Unlock()
+	if running {
+		t.Errorf("background goroutine should not run after Close")
+	}
+	f.Close()
+}
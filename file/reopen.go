@@ -0,0 +1,91 @@
+package file
+
+import (
+	"os"
+	"os/signal"
+)
+
+// Reopen closes the file, and opens the file path again, like after the
+// file is moved by logrotate. The buffered data is written to the file
+// closed. It does nothing after Close.
+func (f *File) Reopen() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return nil
+	}
+	return f.reopen()
+}
+
+func (f *File) reopen() error {
+	f.close()
+	return f.open()
+}
+
+// check reopens the file if it is moved, removed or truncated.
+func (f *File) check() {
+	if !f.closed && f.file != nil && f.moved() {
+		f.reopen()
+	}
+}
+
+// moved reports whether the file path is removed, or points to another
+// file, or the file is shorter than written.
+func (f *File) moved() bool {
+	fi, err := os.Stat(f.FilePath)
+	if err != nil {
+		return os.IsNotExist(err)
+	}
+	cur, err := f.file.Stat()
+	if err != nil || !os.SameFile(fi, cur) {
+		return true
+	}
+	written := f.size
+	if f.bufWriter != nil {
+		written -= int64(f.bufWriter.Buffered())
+	}
+	return fi.Size() < written
+}
+
+// ReopenOnSignal reopens the file on the signals, SIGHUP if none, until
+// Close. It does nothing if no signals, like on js.
+func (f *File) ReopenOnSignal(sigs ...os.Signal) {
+	if len(sigs) == 0 {
+		sigs = reopenSignals
+	}
+	if len(sigs) == 0 {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.sig != nil {
+		signal.Notify(f.sig, sigs...)
+		return
+	}
+	f.sig = make(chan os.Signal, 1)
+	f.sigDone = make(chan struct{})
+	signal.Notify(f.sig, sigs...)
+	go func(sig chan os.Signal, done chan struct{}) {
+		defer close(done)
+		for range sig {
+			f.Reopen()
+		}
+	}(f.sig, f.sigDone)
+}
+
+// stopSignal stops reopening the file on signals, if any.
+func (f *File) stopSignal() {
+	f.mu.Lock()
+	sig, done := f.sig, f.sigDone
+	f.sig, f.sigDone = nil, nil
+	f.mu.Unlock()
+
+	if sig != nil {
+		signal.Stop(sig)
+		close(sig)
+		<-done
+	}
+}
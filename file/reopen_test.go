@@ -0,0 +1,150 @@
+//go:build !windows && !plan9 && !js
+
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// waitFile waits for the file at path to have the content.
+func waitFile(t *testing.T, path, content string) {
+	t.Helper()
+	var b []byte
+	var err error
+	for i := 0; i < 100; i++ {
+		if b, err = os.ReadFile(path); err == nil && string(b) == content {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Errorf("file %s should have %q, has %q %v", filepath.Base(path), content, b, err)
+}
+
+func TestReopen(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	f, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	f.CheckInterval = 0
+
+	f.Write([]byte("before\n"))
+	if err := os.Rename(path, path+".moved"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+	f.Write([]byte("after\n"))
+	f.Sync()
+
+	waitFile(t, path+".moved", "before\n")
+	waitFile(t, path, "after\n")
+}
+
+func TestCheckMoved(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	f, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	f.CheckInterval = 10 * time.Millisecond
+
+	f.Write([]byte("before\n"))
+	f.Sync()
+	if err := os.Rename(path, path+".moved"); err != nil {
+		t.Fatal(err)
+	}
+	// The file is reopened at the path in background.
+	waitFile(t, path, "")
+	f.Write([]byte("after\n"))
+	f.Sync()
+
+	waitFile(t, path+".moved", "before\n")
+	waitFile(t, path, "after\n")
+}
+
+func TestCheckRemovedAndTruncated(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	f, err := OpenFile(path, 10, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	f.CheckInterval = 0
+
+	f.Write([]byte("0123456789\n"))
+	f.Sync()
+	if err := os.Truncate(path, 0); err != nil {
+		t.Fatal(err)
+	}
+	f.mu.Lock()
+	f.check()
+	size := f.size
+	f.mu.Unlock()
+	if size != 0 {
+		t.Errorf("size should be 0 after truncated, is %d", size)
+	}
+
+	os.Remove(path)
+	f.mu.Lock()
+	f.check()
+	f.mu.Unlock()
+	f.Write([]byte("again\n"))
+	f.Sync()
+	waitFile(t, path, "again\n")
+}
+
+func TestReopenOnSignal(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	f, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	f.CheckInterval = 0
+	f.ReopenOnSignal()
+
+	f.Write([]byte("before\n"))
+	f.Sync()
+	if err := os.Rename(path, path+".moved"); err != nil {
+		t.Fatal(err)
+	}
+	syscall.Kill(os.Getpid(), syscall.SIGHUP)
+	waitFile(t, path, "")
+	f.Write([]byte("after\n"))
+	f.Sync()
+
+	waitFile(t, path+".moved", "before\n")
+	waitFile(t, path, "after\n")
+}
+
+func TestReopenAfterClose(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	f, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.ReopenOnSignal()
+	f.Write([]byte("before\n"))
+	f.Close()
+
+	if err := f.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+	f.mu.Lock()
+	opened, running := f.file != nil, f.bgStop != nil || f.sig != nil
+	f.mu.Unlock()
+	if opened || running {
+		t.Errorf("Reopen after Close should do nothing, opened %v, running %v", opened, running)
+	}
+}
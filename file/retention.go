@@ -0,0 +1,106 @@
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// rolledFile is a file in the directory of the file, which looks rolled
+// from it.
+type rolledFile struct {
+	path   string
+	middle string // between "name." and ext, like "1" or "2026-10-17.1"
+	entry  os.DirEntry
+}
+
+// rolledFiles returns the files named "name.<middle>.ext", compressed or
+// not, in the directory of the file.
+func (f *File) rolledFiles() []rolledFile {
+	name, ext := f.splitPath()
+	dir, prefix := filepath.Split(name)
+	entries, _ := os.ReadDir(filepath.Dir(f.FilePath))
+	var files []rolledFile
+	for _, e := range entries {
+		s := strings.TrimSuffix(e.Name(), f.compressExt())
+		if e.IsDir() || len(s) <= len(prefix)+1+len(ext) || !strings.HasPrefix(s, prefix+".") || !strings.HasSuffix(s, ext) {
+			continue
+		}
+		files = append(files, rolledFile{
+			path:   dir + e.Name(),
+			middle: s[len(prefix)+1 : len(s)-len(ext)],
+			entry:  e,
+		})
+	}
+	return files
+}
+
+// isBackup reports whether the middle of the file name is of a backup,
+// rolled by size like "1", or by time like "2026-10-17T15" or "2026-10-17.2".
+func isBackup(middle string) bool {
+	stamp, seq, hasSeq := strings.Cut(middle, ".")
+	if n, err := strconv.Atoi(stamp); err == nil {
+		return n > 0 && !hasSeq
+	}
+	if _, err := time.Parse(RotateDaily.layout(), stamp); err != nil {
+		if _, err := time.Parse(RotateHourly.layout(), stamp); err != nil {
+			return false
+		}
+	}
+	if hasSeq {
+		n, err := strconv.Atoi(seq)
+		return err == nil && n > 0
+	}
+	return true
+}
+
+// retain removes the backups modified before MaxAge, and the oldest backups
+// beyond MaxTotalSize in total. The backups rolled by size and by time,
+// compressed or not, are all counted.
+func (f *File) retain() {
+	if f.MaxAge <= 0 && f.MaxTotalSize <= 0 {
+		return
+	}
+
+	type backupInfo struct {
+		path    string
+		modTime time.Time
+		size    int64
+	}
+	var backups []backupInfo
+	for _, r := range f.rolledFiles() {
+		if !isBackup(r.middle) {
+			continue
+		}
+		fi, err := r.entry.Info()
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backupInfo{r.path, fi.ModTime(), fi.Size()})
+	}
+	// newest first
+	sort.Slice(backups, func(i, j int) bool {
+		if !backups[i].modTime.Equal(backups[j].modTime) {
+			return backups[i].modTime.After(backups[j].modTime)
+		}
+		return backups[i].path < backups[j].path
+	})
+
+	cutoff := f.now().Add(-f.MaxAge)
+	var total int64
+	full := false
+	for _, b := range backups {
+		if !full && f.MaxTotalSize > 0 && total+b.size > f.MaxTotalSize {
+			full = true
+		}
+		if full || (f.MaxAge > 0 && b.modTime.Before(cutoff)) {
+			os.Remove(b.path)
+			f.moveJob(b.path, "")
+			continue
+		}
+		total += b.size
+	}
+}
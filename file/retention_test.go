@@ -0,0 +1,101 @@
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeFiles writes the files of sizes in dir, modified at the times.
+func writeFiles(t *testing.T, dir string, files map[string]time.Time, size int) {
+	t.Helper()
+	for name, mtime := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(strings.Repeat("x", size)), 0660); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestIsBackup(t *testing.T) {
+	for middle, want := range map[string]bool{
+		"1":             true,
+		"12":            true,
+		"2026-10-17":    true,
+		"2026-10-17.3":  true,
+		"2026-10-17T15": true,
+		"0":             false,
+		"1.2":           false,
+		"notes":         false,
+		"2026-10-17.x":  false,
+	} {
+		if got := isBackup(middle); got != want {
+			t.Errorf("isBackup(%q) should be %v, is %v", middle, want, got)
+		}
+	}
+}
+
+func TestRetainMaxAge(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2026, 10, 17, 8, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	writeFiles(t, dir, map[string]time.Time{
+		"app.1.log":             now.Add(-day),
+		"app.2.log.gz":          now.Add(-5 * day),
+		"app.2026-10-10.log.gz": now.Add(-6 * day),
+		"app.2026-10-15.log":    now.Add(-2 * day),
+		"app.notes.log":         now.Add(-30 * day),
+		"other.1.log":           now.Add(-30 * day),
+	}, 10)
+
+	f, err := OpenFile(filepath.Join(dir, "app.log"), 0, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.MaxAge = 3 * day
+	f.Now = func() time.Time { return now }
+	f.Write([]byte("hello\n"))
+	f.Close()
+
+	want := []string{"app.1.log", "app.2026-10-15.log", "app.log", "app.notes.log", "other.1.log"}
+	if got := files(t, dir); !equal(want, got) {
+		t.Errorf("files should be %v, are %v", want, got)
+	}
+}
+
+func TestRetainMaxTotalSize(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now() // app.log is rolled at now
+	writeFiles(t, dir, map[string]time.Time{
+		"app.1.log":          now.Add(-1 * time.Hour),
+		"app.2.log":          now.Add(-2 * time.Hour),
+		"app.3.log.gz":       now.Add(-3 * time.Hour),
+		"app.2026-10-16.log": now.Add(-4 * time.Hour),
+	}, 10)
+
+	f, err := OpenFile(filepath.Join(dir, "app.log"), 10, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.MaxTotalSize = 30
+	f.Write([]byte("hello\n"))
+	if want, got := []string{"app.1.log", "app.2.log", "app.3.log.gz", "app.log"}, files(t, dir); !equal(want, got) {
+		t.Errorf("files should be %v at open, are %v", want, got)
+	}
+
+	// Rolling app.log to app.1.log keeps the newest rolled files only.
+	f.Write([]byte("0123456789\n"))
+	f.Write([]byte("rolled\n"))
+	f.Close()
+	if want, got := []string{"app.1.log", "app.2.log", "app.log"}, files(t, dir); !equal(want, got) {
+		t.Errorf("files should be %v after rolling, are %v", want, got)
+	}
+	if fi, err := os.Stat(filepath.Join(dir, "app.1.log")); err != nil || fi.Size() != 17 {
+		t.Errorf("app.1.log should be rolled from app.log, is %v %v", fi, err)
+	}
+}
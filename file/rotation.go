@@ -0,0 +1,168 @@
+package file
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Rotation is the time interval of rolling the file.
+type Rotation int
+
+const (
+	RotateNone   Rotation = iota // roll by size only
+	RotateHourly                 // name.2006-01-02T15.ext
+	RotateDaily                  // name.2006-01-02.ext
+	RotateWeekly                 // name.2006-01-02.ext of Monday
+)
+
+// ParseRotation returns the rotation by name, "hourly", "daily" or
+// "weekly". The empty name or "none" is RotateNone.
+func ParseRotation(s string) (Rotation, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "none":
+		return RotateNone, nil
+	case "hourly":
+		return RotateHourly, nil
+	case "daily":
+		return RotateDaily, nil
+	case "weekly":
+		return RotateWeekly, nil
+	}
+	return RotateNone, errors.New("unknown rotation " + strconv.Quote(s))
+}
+
+// layout returns the time layout of the period in the rolled file names.
+func (r Rotation) layout() string {
+	if r == RotateHourly {
+		return "2006-01-02T15"
+	}
+	return "2006-01-02"
+}
+
+// start returns the start of the period containing t, in the location of t.
+func (r Rotation) start(t time.Time) time.Time {
+	y, m, d := t.Date()
+	switch r {
+	case RotateHourly:
+		return time.Date(y, m, d, t.Hour(), 0, 0, 0, t.Location())
+	case RotateWeekly:
+		return time.Date(y, m, d-(int(t.Weekday())+6)%7, 0, 0, 0, 0, t.Location())
+	}
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+func (f *File) now() time.Time {
+	if f.Now != nil {
+		return f.Now()
+	}
+	return time.Now()
+}
+
+// splitPath returns the file path without extension, and the extension,
+// like "dir/app" and ".log".
+func (f *File) splitPath() (name, ext string) {
+	ext = filepath.Ext(f.FilePath)
+	return f.FilePath[:len(f.FilePath)-len(ext)], ext
+}
+
+// rotate rolls the file when the period changes, or the size reaches
+// LimitSize in the period.
+func (f *File) rotate() {
+	now := f.now()
+	if f.period.IsZero() {
+		// The file left by the last run belongs to the period of its
+		// modification time.
+		f.period = f.Rotation.start(now)
+		if fi, err := os.Stat(f.FilePath); err == nil && f.file == nil {
+			f.period = f.Rotation.start(fi.ModTime().In(now.Location()))
+		}
+	}
+	p := f.Rotation.start(now)
+	if (p.After(f.period) && f.fileSize() > 0) || (f.LimitSize > 0 && f.size > f.LimitSize) {
+		f.rollingPeriod(f.BackupFiles)
+	}
+	f.period = p
+}
+
+// rollingPeriod renames the file to "name.<period>.ext", or the first of
+// "name.<period>.1.ext", "name.<period>.2.ext", ... not existing, and
+// removes the oldest rolled files beyond n.
+func (f *File) rollingPeriod(n int) {
+	f.close()
+
+	if n < 1 {
+		// no backup file
+		os.Remove(f.FilePath)
+		return
+	}
+
+	name, ext := f.splitPath()
+	stamp := f.period.Format(f.Rotation.layout())
+	slot := name + "." + stamp + ext
+	for i := 1; f.backupExists(slot); i++ {
+		slot = name + "." + stamp + "." + strconv.Itoa(i) + ext
+	}
+	os.Rename(f.FilePath, slot)
+
+	backups := f.periodBackups()
+	for i := 0; i < len(backups)-n; i++ {
+		os.Remove(backups[i].path)
+		f.moveJob(backups[i].path, "")
+	}
+	f.rolled(slot)
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// backup is a rolled file.
+type backup struct {
+	path   string
+	period time.Time
+	seq    int // the suffix number in the period, 0 if none
+}
+
+// periodBackups returns the files rolled by time, compressed or not, oldest
+// first.
+func (f *File) periodBackups() []backup {
+	var backups []backup
+	for _, r := range f.rolledFiles() {
+		b, ok := f.parsePeriodBackup(r.middle)
+		if !ok {
+			continue
+		}
+		b.path = r.path
+		backups = append(backups, b)
+	}
+	sort.Slice(backups, func(i, j int) bool {
+		if !backups[i].period.Equal(backups[j].period) {
+			return backups[i].period.Before(backups[j].period)
+		}
+		return backups[i].seq < backups[j].seq
+	})
+	return backups
+}
+
+// parsePeriodBackup parses the middle of the rolled file name, like
+// "2006-01-02" or "2006-01-02.1".
+func (f *File) parsePeriodBackup(s string) (backup, bool) {
+	stamp, seq, hasSeq := strings.Cut(s, ".")
+	t, err := time.ParseInLocation(f.Rotation.layout(), stamp, f.now().Location())
+	if err != nil {
+		return backup{}, false
+	}
+	b := backup{period: t}
+	if hasSeq {
+		if b.seq, err = strconv.Atoi(seq); err != nil || b.seq < 1 {
+			return backup{}, false
+		}
+	}
+	return b, true
+}
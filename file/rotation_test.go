@@ -0,0 +1,173 @@
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+// clock is the fake time of File.Now.
+type clock struct {
+	t time.Time
+}
+
+func (c *clock) Now() time.Time { return c.t }
+
+func (c *clock) Add(d time.Duration) { c.t = c.t.Add(d) }
+
+// files returns the sorted names of the files in dir.
+func files(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestRotationStart(t *testing.T) {
+	at := time.Date(2026, 10, 17, 13, 45, 10, 0, time.UTC) // Saturday
+	for _, c := range []struct {
+		r    Rotation
+		want time.Time
+	}{
+		{RotateHourly, time.Date(2026, 10, 17, 13, 0, 0, 0, time.UTC)},
+		{RotateDaily, time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)},
+		{RotateWeekly, time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)},
+	} {
+		if got := c.r.start(at); !got.Equal(c.want) {
+			t.Errorf("rotation %d start should be %v, is %v", c.r, c.want, got)
+		}
+	}
+	if got := RotateWeekly.start(time.Date(2026, 10, 12, 1, 0, 0, 0, time.UTC)); got.Day() != 12 {
+		t.Errorf("week should start on Monday 12, is %v", got)
+	}
+}
+
+func TestParseRotation(t *testing.T) {
+	for s, want := range map[string]Rotation{"": RotateNone, "Daily": RotateDaily, " hourly": RotateHourly, "weekly": RotateWeekly} {
+		if r, err := ParseRotation(s); err != nil || r != want {
+			t.Errorf("rotation %q should be %d, is %d %v", s, want, r, err)
+		}
+	}
+	if _, err := ParseRotation("monthly"); err == nil {
+		t.Errorf("rotation monthly should be unknown")
+	}
+}
+
+func TestRotateDaily(t *testing.T) {
+	dir := t.TempDir()
+	c := &clock{time.Date(2026, 10, 16, 23, 0, 0, 0, time.UTC)}
+	f, err := OpenFile(filepath.Join(dir, "app.log"), 0, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.LimitSize = 0
+	f.Rotation = RotateDaily
+	f.Now = c.Now
+
+	f.Write([]byte("day 16\n"))
+	c.Add(2 * time.Hour)
+	f.Write([]byte("day 17\n"))
+	f.Close()
+
+	if want, got := []string{"app.2026-10-16.log", "app.log"}, files(t, dir); !equal(want, got) {
+		t.Fatalf("files should be %v, are %v", want, got)
+	}
+	if b, _ := os.ReadFile(filepath.Join(dir, "app.2026-10-16.log")); string(b) != "day 16\n" {
+		t.Errorf("rolled file should have day 16, has %q", b)
+	}
+	if b, _ := os.ReadFile(filepath.Join(dir, "app.log")); string(b) != "day 17\n" {
+		t.Errorf("file should have day 17, has %q", b)
+	}
+}
+
+func TestRotateDailyAndSize(t *testing.T) {
+	dir := t.TempDir()
+	c := &clock{time.Date(2026, 10, 17, 8, 0, 0, 0, time.UTC)}
+	f, err := OpenFile(filepath.Join(dir, "app.log"), 0, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.LimitSize = 10
+	f.Rotation = RotateDaily
+	f.Now = c.Now
+
+	for i := 0; i < 3; i++ {
+		f.Write([]byte("0123456789ab\n"))
+	}
+	c.Add(24 * time.Hour)
+	f.Write([]byte("next day\n"))
+	f.Close()
+
+	want := []string{"app.2026-10-17.1.log", "app.2026-10-17.2.log", "app.2026-10-17.log", "app.log"}
+	if got := files(t, dir); !equal(want, got) {
+		t.Errorf("files should be %v, are %v", want, got)
+	}
+}
+
+func TestRotateBackupFiles(t *testing.T) {
+	dir := t.TempDir()
+	c := &clock{time.Date(2026, 10, 17, 8, 0, 0, 0, time.UTC)}
+	f, err := OpenFile(filepath.Join(dir, "app.log"), 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.LimitSize = 0
+	f.Rotation = RotateHourly
+	f.Now = c.Now
+
+	for i := 0; i < 5; i++ {
+		f.Write([]byte("hour\n"))
+		c.Add(time.Hour)
+	}
+	f.Close()
+
+	want := []string{"app.2026-10-17T10.log", "app.2026-10-17T11.log", "app.log"}
+	if got := files(t, dir); !equal(want, got) {
+		t.Errorf("files should be %v, are %v", want, got)
+	}
+}
+
+func TestRotateExistingFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	if err := os.WriteFile(path, []byte("last run\n"), 0660); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	os.Chtimes(path, old, old)
+
+	c := &clock{time.Date(2026, 10, 17, 8, 0, 0, 0, time.UTC)}
+	f, err := OpenFile(path, 0, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Rotation = RotateDaily
+	f.Now = c.Now
+	f.Write([]byte("this run\n"))
+	f.Close()
+
+	if want, got := []string{"app.2026-10-16.log", "app.log"}, files(t, dir); !equal(want, got) {
+		t.Errorf("files should be %v, are %v", want, got)
+	}
+}
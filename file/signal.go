@@ -0,0 +1,11 @@
+//go:build !js
+
+package file
+
+import (
+	"os"
+	"syscall"
+)
+
+// reopenSignals is the default signals of ReopenOnSignal.
+var reopenSignals = []os.Signal{syscall.SIGHUP}
@@ -0,0 +1,6 @@
+package file
+
+import "os"
+
+// reopenSignals is empty, as js has no SIGHUP.
+var reopenSignals []os.Signal
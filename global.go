@@ -1,5 +1,7 @@
 package mlog
 
+import "fmt"
+
 var global *Logger = New("root ", nil, "")
 
 func Default() *Logger { return global }
@@ -41,9 +43,16 @@ func Print(a ...any) {
 	global.Loutput(1, Linfo, a...)
 }
 
+func Panic(a ...any) {
+	s := fmt.Sprint(a...)
+	global.Loutput(1, Lpanic, s)
+	global.Flush()
+	panic(s)
+}
+
 func Fatal(a ...any) {
 	global.Loutput(1, Lfatal, a...)
-	global.Close()
+	global.exit()
 }
 
 func Debugln(a ...any) {
@@ -70,9 +79,16 @@ func Println(a ...any) {
 	global.Loutputln(1, Linfo, a...)
 }
 
+func Panicln(a ...any) {
+	s := fmt.Sprintln(a...)
+	global.Loutput(1, Lpanic, s)
+	global.Flush()
+	panic(s)
+}
+
 func Fatalln(a ...any) {
 	global.Loutputln(1, Lfatal, a...)
-	global.Close()
+	global.exit()
 }
 
 func Debugf(format string, a ...any) {
@@ -99,7 +115,49 @@ func Printf(format string, a ...any) {
 	global.Loutputf(1, Linfo, format, a...)
 }
 
+func Panicf(format string, a ...any) {
+	s := fmt.Sprintf(format, a...)
+	global.Loutput(1, Lpanic, s)
+	global.Flush()
+	panic(s)
+}
+
 func Fatalf(format string, a ...any) {
 	global.Loutputf(1, Lfatal, format, a...)
-	global.Close()
+	global.exit()
+}
+
+func Debugw(msg string, kv ...any) {
+	global.Loutputw(1, Ldebug, msg, kv...)
+}
+
+func Tracew(msg string, kv ...any) {
+	global.Loutputw(1, Ltrace, msg, kv...)
+}
+
+func Infow(msg string, kv ...any) {
+	global.Loutputw(1, Linfo, msg, kv...)
+}
+
+func Warnw(msg string, kv ...any) {
+	global.Loutputw(1, Lwarn, msg, kv...)
+}
+
+func Errorw(msg string, kv ...any) {
+	global.Loutputw(1, Lerror, msg, kv...)
+}
+
+func ErrorErr(err error, msg string, kv ...any) {
+	global.Loutputw(1, Lerror, msg, append([]any{Err(err)}, kv...)...)
+}
+
+func Panicw(msg string, kv ...any) {
+	global.Loutputw(1, Lpanic, msg, kv...)
+	global.Flush()
+	panic(msg)
+}
+
+func Fatalw(msg string, kv ...any) {
+	global.Loutputw(1, Lfatal, msg, kv...)
+	global.exit()
 }
@@ -0,0 +1,82 @@
+package mlog
+
+import (
+	"fmt"
+	"os"
+)
+
+// Hook is called with every entry written at the levels it is added for.
+type Hook func(e Entry) error
+
+type levelHook struct {
+	levels []int // severities, nil for all
+	fn     Hook
+}
+
+// AddHook adds the hook called for the entries at levels, like "error",
+// Lfatal, written by the logger and all loggers sharing its outputs. No
+// levels means all levels. The entries sampled away, or not accepted by
+// any output, are not passed to hooks. The errors returned by hooks are
+// passed to the error handler.
+func (l *Logger) AddHook(levels []string, fn Hook) {
+	var ns []int
+	for _, level := range levels {
+		ns = append(ns, ltoi(level))
+	}
+
+	l.core.mu.Lock()
+	defer l.core.mu.Unlock()
+
+	l.core.hooks = append(l.core.hooks, levelHook{ns, fn})
+}
+
+// SetErrorHandler sets the function called with the errors of hooks, for the
+// logger and all loggers sharing its outputs. The errors are printed to
+// os.Stderr by default.
+func (l *Logger) SetErrorHandler(fn func(err error)) {
+	l.core.mu.Lock()
+	defer l.core.mu.Unlock()
+
+	l.core.onError = fn
+}
+
+// runHooks calls the hooks of the severity n with the entry, and passes
+// their errors to onError. It must be called without the core lock held.
+func runHooks(hooks []levelHook, onError func(err error), e *Entry, n int) {
+	for _, h := range hooks {
+		if h.levels != nil && !h.accepts(n) {
+			continue
+		}
+		if err := callHook(h.fn, e); err != nil {
+			handleError(onError, err)
+		}
+	}
+}
+
+func (h levelHook) accepts(n int) bool {
+	for _, level := range h.levels {
+		if level == n {
+			return true
+		}
+	}
+	return false
+}
+
+// callHook calls fn with e, and returns the panic of fn as error.
+func callHook(fn Hook, e *Entry) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("mlog: hook panic: %v", r)
+		}
+	}()
+	return fn(*e)
+}
+
+// handleError passes err to the error handler onError, or prints it if nil.
+func handleError(onError func(err error), err error) {
+	if onError != nil {
+		onError(err)
+		return
+	}
+	fmt.Fprintln(os.Stderr, "mlog: hook error:", err)
+}
@@ -0,0 +1,71 @@
+package mlog
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestHook(t *testing.T) {
+	l := NewLogger("hook: ", &Settings{})
+	l.AddSink("mem", &memSink{})
+
+	counts := make(map[string]int)
+	l.AddHook(nil, func(e Entry) error {
+		counts[e.Level]++
+		return nil
+	})
+	var alerts []string
+	l.AddHook([]string{"error", Lfatal}, func(e Entry) error {
+		alerts = append(alerts, e.Message)
+		return errors.New("alert failed")
+	})
+	l.AddHook([]string{"warn"}, func(e Entry) error {
+		panic("bad hook")
+	})
+	var errs []error
+	l.SetErrorHandler(func(err error) { errs = append(errs, err) })
+
+	child := l.WithName("child: ")
+	child.Info("This is info")
+	child.Warn("This is warn")
+	child.Error("This is error")
+
+	if counts[Linfo] != 1 || counts[Lwarn] != 1 || counts[Lerror] != 1 {
+		t.Errorf("hook should count every level, is %v", counts)
+	}
+	if len(alerts) != 1 || alerts[0] != "This is error" {
+		t.Errorf("error hook should be called once, is %q", alerts)
+	}
+	if len(errs) != 2 || errs[0].Error() != "mlog: hook panic: bad hook" || errs[1].Error() != "alert failed" {
+		t.Errorf("hook errors should be handled, are %v", errs)
+	}
+}
+
+func TestHookUsesLogger(t *testing.T) {
+	l := NewLogger("hook: ", &Settings{})
+	mem := &memSink{}
+	l.AddSink("mem", mem)
+
+	l.AddHook([]string{"error"}, func(e Entry) error {
+		l.SetSampling("debug", Sampling{})
+		l.AddSink("other", &memSink{})
+		l.Warn("mirrored " + e.Message)
+		return errors.New("handled")
+	})
+	l.SetErrorHandler(func(err error) { l.SetExitFunc(nil) })
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		l.Error("This is error")
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("hook using the logger should not deadlock")
+	}
+	if len(mem.entries) != 2 || mem.entries[1].Message != "mirrored This is error" {
+		t.Errorf("hook should log through the logger, entries are %v", mem.entries)
+	}
+}
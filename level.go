@@ -0,0 +1,170 @@
+package mlog
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	Ldebug string = "DEBG "
+	Ltrace string = "TRAC "
+	Linfo  string = "INFO "
+	Lwarn  string = "WARN "
+	Lerror string = "EROR "
+	Lpanic string = "PANIC "
+	Lfatal string = "FATAL "
+)
+
+// The severities of the built-in levels. Entries at or above the level of an
+// output are written.
+const (
+	SeverityDebug = 0
+	SeverityTrace = 10
+	SeverityInfo  = 20
+	SeverityWarn  = 30
+	SeverityError = 40
+	SeverityPanic = 50
+	SeverityFatal = 60
+)
+
+// Level describes a level of entries, built-in or registered by
+// RegisterLevel.
+type Level struct {
+	Name     string // lower case name, like "notice"
+	Tag      string // prefix of text lines, like "NOTE "
+	Severity int    // not negative, like SeverityInfo + 5
+	Color    string // ANSI color of console lines, like "\033[36m", or none
+	Syslog   int    // syslog severity, from 0 (emerg) to 7 (debug)
+}
+
+// levels is the sorted list of levels by severity. It is replaced, never
+// modified, on registering.
+var levels = func() *atomic.Pointer[[]Level] {
+	p := new(atomic.Pointer[[]Level])
+	p.Store(&[]Level{
+		{"debug", Ldebug, SeverityDebug, string(colorDebug), 7},
+		{"trace", Ltrace, SeverityTrace, string(colorTrace), 7},
+		{"info", Linfo, SeverityInfo, "", 6},
+		{"warn", Lwarn, SeverityWarn, string(colorWarn), 4},
+		{"error", Lerror, SeverityError, string(colorError), 3},
+		{"panic", Lpanic, SeverityPanic, string(colorError), 2},
+		{"fatal", Lfatal, SeverityFatal, string(colorError), 1},
+	})
+	return p
+}()
+
+// levelsMu serializes RegisterLevel.
+var levelsMu sync.Mutex
+
+// levelAliases are the other names of the built-in levels.
+var levelAliases = map[string]string{
+	"warning": "warn",
+	"err":     "error",
+}
+
+// RegisterLevel adds the level, like
+//
+//	mlog.RegisterLevel(mlog.Level{
+//		Name:     "notice",
+//		Tag:      "NOTE ",
+//		Severity: mlog.SeverityInfo + 5,
+//		Color:    "\033[36m",
+//		Syslog:   5,
+//	})
+//
+// It can then be used wherever a level is, like Loutput, Settings and
+// SetLevel, by the name or the tag. A space is appended to the tag if
+// missing. The name, tag and severity must not be used by another level.
+// Levels should be registered at init, before they are parsed.
+func RegisterLevel(lv Level) error {
+	lv.Name = strings.ToLower(strings.TrimSpace(lv.Name))
+	if lv.Name == "" || strings.TrimSpace(lv.Tag) == "" {
+		return errors.New("mlog: level name and tag are required")
+	}
+	if !strings.HasSuffix(lv.Tag, " ") {
+		lv.Tag += " "
+	}
+	if lv.Severity < 0 || lv.Severity >= math.MaxInt32 {
+		return errors.New("mlog: level " + strconv.Quote(lv.Name) + " severity out of range")
+	}
+	if lv.Syslog < 0 || lv.Syslog > 7 {
+		return errors.New("mlog: level " + strconv.Quote(lv.Name) + " syslog severity out of range")
+	}
+
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+
+	key := strings.ToLower(strings.TrimSpace(lv.Tag))
+	for _, l := range *levels.Load() {
+		if l.Severity == lv.Severity {
+			return errors.New("mlog: level " + strconv.Quote(l.Name) + " has the severity of " + strconv.Quote(lv.Name))
+		}
+		if n := strings.ToLower(strings.TrimSpace(l.Tag)); lv.Name == l.Name || lv.Name == n || key == l.Name || key == n {
+			return errors.New("mlog: level " + strconv.Quote(l.Name) + " has the name or tag of " + strconv.Quote(lv.Name))
+		}
+	}
+	if _, ok := levelAliases[lv.Name]; ok {
+		return errors.New("mlog: level " + strconv.Quote(lv.Name) + " is an alias")
+	}
+	list := append([]Level(nil), *levels.Load()...)
+	list = append(list, lv)
+	sort.Slice(list, func(i, j int) bool { return list[i].Severity < list[j].Severity })
+	levels.Store(&list)
+	return nil
+}
+
+// ParseLevel returns the level by name or tag, like "warn" or Lwarn.
+func ParseLevel(s string) (Level, error) {
+	n, err := parseLevel(s)
+	if err != nil {
+		return Level{}, err
+	}
+	return levelOf(n), nil
+}
+
+// levelOf returns the level with the highest severity not above n, or the
+// lowest level.
+func levelOf(n int) Level {
+	list := *levels.Load()
+	i := sort.Search(len(list), func(i int) bool { return list[i].Severity > n })
+	if i == 0 {
+		return list[0]
+	}
+	return list[i-1]
+}
+
+// levelName returns the lower case name of the level, like "info".
+func levelName(level string) string {
+	return levelOf(ltoi(level)).Name
+}
+
+// parseLevel returns the severity of s, like "debug", "warn", "EROR ".
+func parseLevel(s string) (int, error) {
+	list := *levels.Load()
+	for _, l := range list {
+		if s == l.Tag {
+			return l.Severity, nil
+		}
+	}
+	name := strings.ToLower(strings.Trim(s, " \r\n"))
+	if alias, ok := levelAliases[name]; ok {
+		name = alias
+	}
+	for _, l := range list {
+		if name == l.Name || name == strings.ToLower(strings.TrimSpace(l.Tag)) {
+			return l.Severity, nil
+		}
+	}
+	return SeverityInfo, errors.New("mlog: unknown level " + strconv.Quote(s))
+}
+
+// ltoi returns the severity of s, or SeverityInfo if unknown.
+func ltoi(s string) int {
+	n, _ := parseLevel(s)
+	return n
+}
@@ -0,0 +1,84 @@
+package mlog
+
+import (
+	"bytes"
+	"log/slog"
+	"sync"
+	"testing"
+)
+
+const Lnotice = "NOTE "
+
+var registerNotice sync.Once
+
+func notice(t *testing.T) {
+	t.Helper()
+	registerNotice.Do(func() {
+		err := RegisterLevel(Level{
+			Name:     "notice",
+			Tag:      "NOTE",
+			Severity: SeverityInfo + 5,
+			Color:    "\033[36m",
+			Syslog:   5,
+		})
+		if err != nil {
+			t.Fatalf("notice level should be registered, %v", err)
+		}
+	})
+}
+
+func TestRegisterLevel(t *testing.T) {
+	notice(t)
+
+	for _, s := range []string{"notice", "NOTE ", "note", " Notice "} {
+		if n, err := parseLevel(s); err != nil || n != SeverityInfo+5 {
+			t.Errorf("level %q should be parsed, is %d %v", s, n, err)
+		}
+	}
+	if got := levelName(Lnotice); got != "notice" {
+		t.Errorf("level name should be notice, is %q", got)
+	}
+	if got := levelOf(ltoi("notice")).Syslog; got != 5 {
+		t.Errorf("syslog severity should be 5, is %d", got)
+	}
+	if got := SlogLevel("notice"); got != slog.LevelInfo+2 {
+		t.Errorf("slog level should be %v, is %v", slog.LevelInfo+2, got)
+	}
+	if got := LevelFromSlog(SlogLevel("notice")); got != Lnotice {
+		t.Errorf("level should map back, is %q", got)
+	}
+
+	for _, lv := range []Level{
+		{Name: "notice", Tag: "NTC ", Severity: 26},
+		{Name: "other", Tag: "NOTE ", Severity: 26},
+		{Name: "other", Tag: "OTHR ", Severity: SeverityWarn},
+		{Name: "warning", Tag: "OTHR ", Severity: 26},
+		{Name: "other", Tag: "OTHR ", Severity: -1},
+		{Name: "other", Tag: "OTHR ", Severity: 26, Syslog: 8},
+		{Name: "", Tag: "OTHR ", Severity: 26},
+	} {
+		if err := RegisterLevel(lv); err == nil {
+			t.Errorf("level %+v should not be registered", lv)
+		}
+	}
+}
+
+func TestCustomLevelOutput(t *testing.T) {
+	notice(t)
+
+	var buf bytes.Buffer
+	l := &Logger{name: "test: ", core: &core{}}
+	s := NewEncoderSink(&buf, "notice", &TextEncoder{})
+	s.color = true
+	l.AddSink("console", s)
+
+	l.Info("This is info")
+	l.Loutput(0, "notice", "This is notice")
+	l.Warn("This is warn")
+	if want, got := "\033[36mNOTE test: This is notice\033[0m\n\033[33mWARN test: This is warn\033[0m\n", buf.String(); want != got {
+		t.Errorf("logger output should match %q is %q", want, got)
+	}
+	if got := l.Level("console"); got != "notice" {
+		t.Errorf("console level should be %q, is %q", "notice", got)
+	}
+}
@@ -0,0 +1,119 @@
+package mlog
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+// LevelHandler is an http.Handler which views and changes the output levels
+// of loggers at runtime. The body is a JSON object of levels by sink name
+// by logger name, like
+//
+//	{"root": {"console": "debug", "file": "info", "*": ""}, "db": {"*": "warn"}}
+//
+// The registered loggers, see Get, are listed by their dotted names. Their
+// "*" is the level of SetNameLevel used for all outputs, "" if none.
+//
+// GET returns the current levels. PUT or POST changes the levels given in
+// the body, and returns the levels after change.
+type LevelHandler struct {
+	l *Logger
+}
+
+// NewLevelHandler returns the handler of the logger l and the registered
+// loggers.
+func NewLevelHandler(l *Logger) *LevelHandler {
+	return &LevelHandler{l: l}
+}
+
+// nameLevelKey is the key of the level set by name in the levels of a logger.
+const nameLevelKey = "*"
+
+func (h *LevelHandler) levels() map[string]map[string]string {
+	self := h.l.Levels()
+	if h.l.level != nil {
+		self[nameLevelKey] = NameLevel(h.l.path)
+	}
+	levels := map[string]map[string]string{
+		trimName(h.l.name): self,
+	}
+	for _, name := range Loggers() {
+		if _, ok := levels[name]; !ok {
+			levels[name] = map[string]string{nameLevelKey: NameLevel(name)}
+		}
+	}
+	return levels
+}
+
+// path returns the registry name of the logger listed by name.
+func (h *LevelHandler) path(name string) string {
+	if name == trimName(h.l.name) {
+		return h.l.path
+	}
+	return name
+}
+
+// set validates all levels in req, and that the sinks can change levels,
+// before changing any of them.
+func (h *LevelHandler) set(req map[string]map[string]string) error {
+	levels := h.levels()
+	for name, sinks := range req {
+		cur, ok := levels[name]
+		if !ok {
+			return errors.New("mlog: unknown logger " + strconv.Quote(name))
+		}
+		for sink, level := range sinks {
+			if _, ok := cur[sink]; !ok {
+				return errors.New("mlog: unknown sink " + strconv.Quote(sink))
+			}
+			if sink == nameLevelKey {
+				if level == "" {
+					continue
+				}
+			} else if _, ok := h.l.Sink(sink).(LevelSetter); !ok {
+				return errors.New("mlog: sink " + sink + " level can not be changed")
+			}
+			if _, err := parseLevel(level); err != nil {
+				return err
+			}
+		}
+	}
+	for name, sinks := range req {
+		for sink, level := range sinks {
+			var err error
+			if sink == nameLevelKey {
+				err = SetNameLevel(h.path(name), level)
+			} else {
+				err = h.l.SetLevel(sink, level)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (h *LevelHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut, http.MethodPost:
+		var req map[string]map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "mlog: bad request body: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := h.set(req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, PUT, POST")
+		http.Error(w, "mlog: method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(h.levels())
+}
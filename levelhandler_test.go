@@ -0,0 +1,73 @@
+package mlog
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func serveLevels(h http.Handler, method, body string) (int, string) {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(method, "/log/level", strings.NewReader(body)))
+	return rec.Code, strings.TrimSpace(rec.Body.String())
+}
+
+func TestLevelHandler(t *testing.T) {
+	ResetRegistry()
+	defer ResetRegistry()
+
+	var buf bytes.Buffer
+	l := New("app: ", log.New(&buf, "", 0), Linfo)
+	l.AddSink("custom", &memSink{})
+	h := NewLevelHandler(l)
+
+	serve := func(method, body string) (int, string) {
+		return serveLevels(h, method, body)
+	}
+
+	if code, body := serve(http.MethodGet, ""); code != http.StatusOK || body != `{"app":{"console":"info","custom":"debug"}}` {
+		t.Errorf("get should return levels, is %d %s", code, body)
+	}
+	if code, body := serve(http.MethodPut, `{"app":{"console":"EROR "}}`); code != http.StatusOK || body != `{"app":{"console":"error","custom":"debug"}}` {
+		t.Errorf("put should change levels, is %d %s", code, body)
+	}
+	for _, body := range []string{`{"app":{"console":"verbose"}}`, `{"app":{"file":"info"}}`, `{"db":{"console":"info"}}`, `{"app":{"console":"warn","custom":"warn"}}`, `{`} {
+		if code, _ := serve(http.MethodPost, body); code != http.StatusBadRequest {
+			t.Errorf("post %s should be bad request, is %d", body, code)
+		}
+	}
+	if want, got := "error", l.Level("console"); want != got {
+		t.Errorf("level should be unchanged %q is %q", want, got)
+	}
+	if code, _ := serve(http.MethodDelete, ""); code != http.StatusMethodNotAllowed {
+		t.Errorf("delete should not be allowed, is %d", code)
+	}
+}
+
+func TestLevelHandlerRegistry(t *testing.T) {
+	ResetRegistry()
+	defer ResetRegistry()
+
+	Get("db.pool")
+	h := NewLevelHandler(Get("db"))
+	if code, body := serveLevels(h, http.MethodGet, ""); code != http.StatusOK || body != `{"db":{"*":"","console":"debug"},"db.pool":{"*":""}}` {
+		t.Errorf("get should return levels of registered loggers, is %d %s", code, body)
+	}
+	if code, body := serveLevels(h, http.MethodPut, `{"db":{"*":"warn"}}`); code != http.StatusOK || body != `{"db":{"*":"warn","console":"debug"},"db.pool":{"*":"warn"}}` {
+		t.Errorf("put should set level by name, is %d %s", code, body)
+	}
+	for _, body := range []string{`{"db.pool":{"console":"info"}}`, `{"db.pool":{"*":"verbose"}}`, `{"cache":{"*":"info"}}`} {
+		if code, _ := serveLevels(h, http.MethodPost, body); code != http.StatusBadRequest {
+			t.Errorf("post %s should be bad request, is %d", body, code)
+		}
+	}
+	if code, _ := serveLevels(h, http.MethodPut, `{"db.pool":{"*":"error"}}`); code != http.StatusOK || NameLevel("db.pool") != "error" {
+		t.Errorf("put should set level of db.pool, is %d %q", code, NameLevel("db.pool"))
+	}
+	if code, _ := serveLevels(h, http.MethodPut, `{"db":{"*":""}}`); code != http.StatusOK || NameLevel("db") != "" {
+		t.Errorf("put should remove level of db, is %d %q", code, NameLevel("db"))
+	}
+}
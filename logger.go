@@ -4,14 +4,17 @@
 package mlog
 
 import (
+	"errors"
 	"fmt"
-	"io"
 	stdlog "log"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/ccpaging/mlog/file"
 )
@@ -20,46 +23,59 @@ var (
 	LstdFlags = stdlog.LstdFlags | stdlog.Lmsgprefix
 )
 
-const (
-	Ldebug string = "DEBG "
-	Ltrace string = "TRAC "
-	Linfo  string = "INFO "
-	Lwarn  string = "WARN "
-	Lerror string = "EROR "
-	Lfatal string = "FATAL "
-)
-
-var levelStrings = []string{Ldebug, Ltrace, Linfo, Lwarn, Lerror, Lfatal}
-
-func ltoi(s string) int {
-	switch strings.ToLower(strings.Trim(s, " \r\n")) {
-	case "debug", Ldebug:
-		return 0
-	case "trace", Ltrace:
-		return 1
-	case "info", Linfo:
-		return 2
-	case "warn", "warning", Lwarn:
-		return 3
-	case "err", "error", Lerror:
-		return 4
-	case "fatal", Lfatal:
-		return 5
-	default:
-	}
-	return 2
-}
-
 type Settings struct {
 	EnableConsole    bool
 	ConsoleLevel     string
 	ConsoleAnsiColor bool
+	ConsoleFormat    string // "text", "json" or "logfmt"
 
 	EnableFile      bool
 	FileLevel       string
 	FileLocation    string
 	FileLimitSize   string
 	FileBackupCount int
+	FileFormat      string // "text", "json" or "logfmt"
+	// FileRotation rolls the file "hourly", "daily" or "weekly" too. The
+	// empty or zero FileLimitSize rolls it by time only.
+	FileRotation string
+	// FileCompress compresses the rolled files with gzip.
+	FileCompress bool
+	// FileMaxAge removes the rolled files older than it, like "30d" or
+	// "12h". FileMaxTotalSize removes the oldest rolled files beyond it in
+	// total, like "1G". Empty means no limit.
+	FileMaxAge       string
+	FileMaxTotalSize string
+	// FileFlushInterval is the interval of flushing the buffered file, like
+	// "500ms". Empty is file.DefaultFlushInterval, "0" disables it.
+	FileFlushInterval string
+	// FileReopenOnSignal reopens the file on SIGHUP, like after logrotate
+	// moves it.
+	FileReopenOnSignal bool
+
+	// Syslog is dialed by network and address, see syslog.Dial. The empty
+	// network is the local syslog server. The facility is like "local0".
+	EnableSyslog   bool
+	SyslogNetwork  string
+	SyslogAddress  string
+	SyslogFacility string
+	SyslogTag      string
+	SyslogLevel    string
+
+	// Caller is how console and file outputs write the caller of entries,
+	// "none", "short", "package", "module" or "full". CallerFunction adds
+	// the function name. StacktraceLevel captures the stack trace of
+	// entries at or above it, like "error".
+	Caller          string
+	CallerFunction  bool
+	StacktraceLevel string
+
+	// Async writes outputs in background goroutines with the queues of
+	// AsyncQueueSize entries. See AsyncSink.
+	Async          bool
+	AsyncQueueSize int
+
+	// Sampling limits the repeated entries by level, like "debug".
+	Sampling map[string]Sampling
 }
 
 func DefaultSettings() *Settings {
@@ -67,22 +83,44 @@ func DefaultSettings() *Settings {
 		EnableConsole:    true,
 		ConsoleLevel:     Ldebug,
 		ConsoleAnsiColor: false,
+		ConsoleFormat:    "text",
 		EnableFile:       false,
 		FileLevel:        Linfo,
 		FileLocation:     "",
 		FileLimitSize:    "10M",
 		FileBackupCount:  7,
+		FileFormat:       "text",
+		EnableSyslog:     false,
+		SyslogFacility:   "user",
+		SyslogLevel:      Linfo,
+		Async:            false,
+		AsyncQueueSize:   DefaultQueueSize,
 	}
 }
 
-type Logger struct {
-	mu   sync.Mutex
+// core is the sinks shared by a logger and all loggers derived from it.
+type core struct {
+	mu      sync.RWMutex
+	sinks   []namedSink
+	sampler *sampler
+	hooks   []levelHook
+	onError func(err error)
+	exit    func(code int)
+	stack   atomic.Int32 // severity + 1 capturing stack trace, 0 if none
+}
+
+type namedSink struct {
 	name string
-	core map[string]*stdlog.Logger
-	cw   io.Writer
-	fw   *file.File
-	cal  int // the level index of console output
-	fal  int // the level index of file output
+	Sink
+}
+
+type Logger struct {
+	name   string
+	core   *core
+	fields []Field
+
+	path  string        // dotted name in registry, "" if not registered
+	level *atomic.Int32 // severity overriding outputs', negative if none
 }
 
 func New(name string, root *stdlog.Logger, level string) *Logger {
@@ -90,28 +128,15 @@ func New(name string, root *stdlog.Logger, level string) *Logger {
 		root = stdlog.Default()
 		root.SetFlags(LstdFlags)
 	}
-	core := make(map[string]*stdlog.Logger)
-	for _, level := range levelStrings {
-		core[level] = root
+	if level == "" {
+		level = Ldebug
 	}
-	return &Logger{
+	l := &Logger{
 		name: name,
-		core: core,
-		cw:   root.Writer(),
-		fw:   nil,
-		cal:  ltoi(level),
-		fal:  ltoi(level),
-	}
-}
-
-func newConsoleWriter(s *Settings) io.Writer {
-	if !s.EnableConsole {
-		return nil
+		core: &core{},
 	}
-	if s.ConsoleAnsiColor {
-		return &ansiTerm{os.Stderr}
-	}
-	return os.Stderr
+	l.AddSink("console", newStdLogSink(root, level))
+	return l
 }
 
 func strToNumSuffix(s string, base int64) (int64, error) {
@@ -133,10 +158,16 @@ func strToNumSuffix(s string, base int64) (int64, error) {
 	return n * multi, err
 }
 
-func newFileWriter(s *Settings) *file.File {
-	if !s.EnableFile {
-		return nil
+// strToDuration parses s as time.ParseDuration, or as days like "30d".
+func strToDuration(s string) (time.Duration, error) {
+	if n, ok := strings.CutSuffix(s, "d"); ok {
+		days, err := strconv.ParseFloat(n, 64)
+		return time.Duration(days * float64(24*time.Hour)), err
 	}
+	return time.ParseDuration(s)
+}
+
+func newFileWriter(s *Settings) (*file.File, error) {
 	limitSize, _ := strToNumSuffix(s.FileLimitSize, 1024)
 	if s.FileLocation == "" {
 		fileName := os.Args[0]
@@ -145,102 +176,362 @@ func newFileWriter(s *Settings) *file.File {
 	}
 	fw, err := file.OpenFile(s.FileLocation, limitSize, s.FileBackupCount)
 	if err != nil {
-		fmt.Fprintln(os.Stderr, "Open file", err)
+		return nil, err
 	}
-	return fw
-}
-
-func (l *Logger) levelWriter(n int) io.Writer {
-	isConsole := false
-	if l.cw != nil && n >= l.cal {
-		isConsole = true
+	fw.Rotation, _ = file.ParseRotation(s.FileRotation)
+	if s.FileCompress {
+		fw.Compress = file.Gzip
 	}
-	isFile := false
-	if l.fw != nil && n >= l.fal {
-		isFile = true
+	if s.FileMaxAge != "" {
+		fw.MaxAge, _ = strToDuration(s.FileMaxAge)
 	}
-	if isConsole && isFile {
-		return io.MultiWriter(l.cw, l.fw)
-	} else if isConsole {
-		return l.cw
-	} else if isFile {
-		return l.fw
+	fw.MaxTotalSize, _ = strToNumSuffix(s.FileMaxTotalSize, 1024)
+	if s.FileFlushInterval != "" {
+		fw.FlushInterval, _ = strToDuration(s.FileFlushInterval)
 	}
-	return nil
+	if s.FileReopenOnSignal {
+		fw.ReopenOnSignal()
+	}
+	if fw.Rotation != file.RotateNone && limitSize == 0 {
+		fw.LimitSize = 0
+	}
+	return fw, nil
+}
+
+// newSinks returns the outputs created from the settings. The outputs which
+// fail are left out, and their errors are returned. If verify, the file is
+// tried to open now rather than on the first write, to report the error.
+func newSinks(s *Settings, verify bool) ([]namedSink, error) {
+	caller, _ := parseCaller(s.Caller)
+	var sinks []namedSink
+	var errs []error
+	add := func(name string, sink Sink) {
+		if s.Async {
+			sink = NewAsyncSink(sink, s.AsyncQueueSize)
+		}
+		sinks = append(sinks, namedSink{name, sink})
+	}
+	if s.EnableConsole {
+		cs := NewEncoderSink(os.Stderr, s.ConsoleLevel, newEncoder(s.ConsoleFormat, LstdFlags, caller, s.CallerFunction))
+		cs.color = s.ConsoleAnsiColor
+		add("console", cs)
+	}
+	if s.EnableFile {
+		fw, err := newFileWriter(s)
+		if err == nil && verify {
+			var f *os.File
+			if f, err = os.OpenFile(fw.FilePath, file.DefaultFileFlag, fw.FileMode); err == nil {
+				f.Close()
+			} else {
+				fw.Close()
+			}
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("Open file %w", err))
+		} else {
+			add("file", newFileSink(fw, s.FileLevel, newEncoder(s.FileFormat, LstdFlags, caller, s.CallerFunction)))
+		}
+	}
+	if s.EnableSyslog {
+		if sw, err := newSyslogSink(s); err != nil {
+			errs = append(errs, fmt.Errorf("Dial syslog %w", err))
+		} else {
+			add("syslog", sw)
+		}
+	}
+	return sinks, errors.Join(errs...)
 }
 
 func NewLogger(name string, s *Settings) *Logger {
 	l := &Logger{
 		name: name,
-		core: make(map[string]*stdlog.Logger),
-		cw:   newConsoleWriter(s),
-		fw:   newFileWriter(s),
-		cal:  ltoi(s.ConsoleLevel),
-		fal:  ltoi(s.FileLevel),
+		core: &core{},
 	}
+	l.SetStacktrace(s.StacktraceLevel)
+	sinks, err := newSinks(s, false)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	l.core.sinks = sinks
+	for level, cfg := range s.Sampling {
+		l.SetSampling(level, cfg)
+	}
+	return l
+}
 
-	for i, k := range levelStrings {
-		if w := l.levelWriter(i); w != nil {
-			l.core[k] = stdlog.New(w, "", LstdFlags)
+// AddSink adds the output named name to the logger and all loggers sharing
+// its core. A sink with the same name is replaced without closing.
+func (l *Logger) AddSink(name string, s Sink) {
+	l.core.mu.Lock()
+	defer l.core.mu.Unlock()
+
+	for i := range l.core.sinks {
+		if l.core.sinks[i].name == name {
+			l.core.sinks[i].Sink = s
+			return
 		}
 	}
-	return l
+	l.core.sinks = append(l.core.sinks, namedSink{name, s})
 }
 
-func (l *Logger) WithName(name string) *Logger {
-	l.mu.Lock()
-	defer l.mu.Unlock()
+// Sink returns the output named name, or nil.
+func (l *Logger) Sink(name string) Sink {
+	l.core.mu.RLock()
+	defer l.core.mu.RUnlock()
 
-	return &Logger{
-		name: name,
-		core: l.core,
-		cw:   l.cw,
-		fw:   l.fw,
-		cal:  l.cal,
-		fal:  l.fal,
+	for _, s := range l.core.sinks {
+		if s.name == name {
+			return s.Sink
+		}
+	}
+	return nil
+}
+
+// SetLevel changes the level of the output named sink, like "console",
+// "file". It takes effect immediately for all loggers sharing the output.
+func (l *Logger) SetLevel(sink string, level string) error {
+	s := l.Sink(sink)
+	if s == nil {
+		return errors.New("mlog: no sink " + sink)
+	}
+	ls, ok := s.(LevelSetter)
+	if !ok {
+		return errors.New("mlog: sink " + sink + " level can not be changed")
+	}
+	if _, err := parseLevel(level); err != nil {
+		return err
+	}
+	ls.SetLevel(level)
+	return nil
+}
+
+// Level returns the level name of the output named sink, like "debug", or
+// "" if not found.
+func (l *Logger) Level(sink string) string {
+	if s := l.Sink(sink); s != nil {
+		return levelOf(s.Level()).Name
 	}
+	return ""
+}
+
+// Levels returns the level names of all outputs by the sink name,
+// like {"console": "debug", "file": "info"}.
+func (l *Logger) Levels() map[string]string {
+	l.core.mu.RLock()
+	defer l.core.mu.RUnlock()
+
+	levels := make(map[string]string, len(l.core.sinks))
+	for _, s := range l.core.sinks {
+		levels[s.name] = levelOf(s.Level()).Name
+	}
+	return levels
+}
+
+func (l *Logger) clone() *Logger {
+	c := *l
+	return &c
+}
+
+func (l *Logger) WithName(name string) *Logger {
+	c := l.clone()
+	c.name = name
+	return c
+}
+
+// With returns a logger sharing the outputs of l, which adds the fields
+// in kv to every entry. kv is alternating keys and values, or Field.
+func (l *Logger) With(kv ...any) *Logger {
+	c := l.clone()
+	c.fields = appendFields(l.fields[:len(l.fields):len(l.fields)], kv)
+	return c
 }
 
 func (l *Logger) CopyFrom(in *Logger) {
-	l.mu.Lock()
-	defer l.mu.Unlock()
+	if l.core == in.core {
+		return
+	}
+	in.core.mu.RLock()
+	sinks := append([]namedSink(nil), in.core.sinks...)
+	sampler := in.core.sampler
+	hooks := append([]levelHook(nil), in.core.hooks...)
+	onError := in.core.onError
+	exit := in.core.exit
+	in.core.mu.RUnlock()
+	l.core.stack.Store(in.core.stack.Load())
+
+	l.core.mu.Lock()
+	defer l.core.mu.Unlock()
+
+	l.core.sinks = sinks
+	l.core.sampler = sampler
+	l.core.hooks = hooks
+	l.core.onError = onError
+	l.core.exit = exit
+}
+
+// Dropped returns the number of entries dropped by asynchronous outputs.
+func (l *Logger) Dropped() uint64 {
+	l.core.mu.RLock()
+	defer l.core.mu.RUnlock()
+
+	var n uint64
+	for _, s := range l.core.sinks {
+		if d, ok := s.Sink.(interface{ Dropped() uint64 }); ok {
+			n += d.Dropped()
+		}
+	}
+	return n
+}
 
-	// clear map
-	for key := range l.core {
-		delete(l.core, key)
+// flushSampler writes the summaries of sampling.
+// It must be called with l.core.mu held.
+func (l *Logger) flushSampler() {
+	if l.core.sampler == nil {
+		return
 	}
-	// copy from
-	for key, value := range in.core {
-		l.core[key] = value
+	for _, e := range l.core.sampler.flush(time.Now()) {
+		l.core.write(e, ltoi(e.Level), -1)
 	}
+}
+
+// Flush flushes all outputs.
+func (l *Logger) Flush() {
+	l.core.mu.RLock()
+	defer l.core.mu.RUnlock()
 
-	l.cw = in.cw
-	l.fw = in.fw
-	l.cal = in.cal
-	l.fal = in.fal
+	l.flushSampler()
+	for _, s := range l.core.sinks {
+		s.Flush()
+	}
+}
+
+// SetExitFunc sets the function called by Fatal after closing outputs, for
+// the logger and all loggers sharing its outputs. It is os.Exit by default.
+func (l *Logger) SetExitFunc(fn func(code int)) {
+	l.core.mu.Lock()
+	defer l.core.mu.Unlock()
+
+	l.core.exit = fn
+}
+
+// exit closes all outputs, then calls the exit function with code 1.
+func (l *Logger) exit() {
+	l.core.mu.RLock()
+	exit := l.core.exit
+	l.core.mu.RUnlock()
+
+	l.Close()
+	if exit == nil {
+		exit = os.Exit
+	}
+	exit(1)
 }
 
 func (l *Logger) Close() {
-	l.mu.Lock()
-	defer l.mu.Unlock()
+	l.core.mu.Lock()
+	defer l.core.mu.Unlock()
 
-	if l.fw != nil {
-		l.fw.Close()
+	l.flushSampler()
+	for _, s := range l.core.sinks {
+		s.Close()
 	}
 	// Clear data
-	l.fw = nil
-	// clear map
-	for key := range l.core {
-		delete(l.core, key)
+	l.core.sinks = nil
+}
+
+// overrideLevel returns the severity set by SetNameLevel for the logger,
+// or -1 if the levels of outputs are used.
+func (l *Logger) overrideLevel() int {
+	if l.level == nil {
+		return -1
 	}
+	return int(l.level.Load())
 }
 
-func (l *Logger) Loutput(calldepth int, level string, a ...any) {
-	l.mu.Lock()
-	defer l.mu.Unlock()
+// enabled reports whether any output accepts the severity n.
+func (l *Logger) enabled(n int) bool {
+	o := l.overrideLevel()
+
+	l.core.mu.RLock()
+	defer l.core.mu.RUnlock()
+
+	for _, s := range l.core.sinks {
+		if (o >= 0 && n >= o) || (o < 0 && n >= s.Level()) {
+			return true
+		}
+	}
+	return false
+}
 
-	if ll, ok := l.core[level]; ok {
-		ll.Output(2+calldepth, level+l.name+fmt.Sprint(a...))
+// write writes the entry of severity n to every output accepting it.
+// The override level o is used instead of the outputs' if not negative.
+// It must be called with c.mu held.
+func (c *core) write(e *Entry, n int, o int) {
+	for _, s := range c.sinks {
+		if (o >= 0 && n >= o) || (o < 0 && n >= s.Level()) {
+			s.Write(e)
+		}
+	}
+}
+
+// emit writes the entry to every output accepting its level, and calls the
+// hooks, unless it is sampled away.
+func (l *Logger) emit(e *Entry) {
+	n := ltoi(e.Level)
+	if hooks, onError, ok := l.write(e, n); ok {
+		runHooks(hooks, onError, e, n)
+	}
+}
+
+// write writes the entry of severity n to every output accepting it, unless
+// it is sampled away, and returns the hooks and the error handler to call
+// with it. The hooks are called without the lock, so they may use the logger.
+func (l *Logger) write(e *Entry, n int) (hooks []levelHook, onError func(error), ok bool) {
+	o := l.overrideLevel()
+
+	l.core.mu.RLock()
+	defer l.core.mu.RUnlock()
+
+	if l.core.sampler != nil {
+		ok, summary := l.core.sampler.sample(n, e.Message, e.Time)
+		if summary != nil {
+			l.core.write(summary, n, -1)
+		}
+		if !ok {
+			return nil, nil, false
+		}
+	}
+	l.core.write(e, n, o)
+	return l.core.hooks, l.core.onError, true
+}
+
+// output builds the entry with the caller skip frames above output.
+func (l *Logger) output(skip int, level string, msg string, kv []any) {
+	n := ltoi(level)
+	e := &Entry{
+		Time:    time.Now(),
+		Level:   levelOf(n).Tag,
+		Name:    l.name,
+		Message: msg,
+		Fields:  l.fields,
+	}
+	if len(kv) > 0 {
+		e.Fields = appendFields(l.fields[:len(l.fields):len(l.fields)], kv)
+	}
+	var pcs [1]uintptr
+	if runtime.Callers(skip+2, pcs[:]) > 0 {
+		e.PC = pcs[0]
+	}
+	if l.stackEnabled(n) {
+		stack := make([]uintptr, maxStackDepth)
+		e.Stack = stack[:runtime.Callers(skip+2, stack)]
+	}
+	l.emit(e)
+}
+
+func (l *Logger) Loutput(calldepth int, level string, a ...any) {
+	if l.enabled(ltoi(level)) {
+		l.output(1+calldepth, level, fmt.Sprint(a...), nil)
 	}
 }
 
@@ -272,17 +563,21 @@ func (l *Logger) Print(a ...any) {
 	l.Loutput(1, Linfo, a...)
 }
 
+func (l *Logger) Panic(a ...any) {
+	s := fmt.Sprint(a...)
+	l.Loutput(1, Lpanic, s)
+	l.Flush()
+	panic(s)
+}
+
 func (l *Logger) Fatal(a ...any) {
 	l.Loutput(1, Lfatal, a...)
-	l.Close()
+	l.exit()
 }
 
 func (l *Logger) Loutputln(calldepth int, level string, a ...any) {
-	l.mu.Lock()
-	defer l.mu.Unlock()
-
-	if ll, ok := l.core[level]; ok {
-		ll.Output(2+calldepth, level+l.name+fmt.Sprintln(a...))
+	if l.enabled(ltoi(level)) {
+		l.output(1+calldepth, level, fmt.Sprintln(a...), nil)
 	}
 }
 
@@ -310,17 +605,21 @@ func (l *Logger) Println(a ...any) {
 	l.Loutputln(1, Linfo, a...)
 }
 
+func (l *Logger) Panicln(a ...any) {
+	s := fmt.Sprintln(a...)
+	l.Loutput(1, Lpanic, s)
+	l.Flush()
+	panic(s)
+}
+
 func (l *Logger) Fatalln(a ...any) {
 	l.Loutputln(1, Lfatal, a...)
-	l.Close()
+	l.exit()
 }
 
 func (l *Logger) Loutputf(calldepth int, level string, format string, a ...any) {
-	l.mu.Lock()
-	defer l.mu.Unlock()
-
-	if ll, ok := l.core[level]; ok {
-		ll.Output(2+calldepth, level+l.name+fmt.Sprintf(format, a...))
+	if l.enabled(ltoi(level)) {
+		l.output(1+calldepth, level, fmt.Sprintf(format, a...), nil)
 	}
 }
 
@@ -348,7 +647,51 @@ func (l *Logger) Printf(format string, a ...any) {
 	l.Loutputf(1, Linfo, format, a...)
 }
 
+func (l *Logger) Panicf(format string, a ...any) {
+	s := fmt.Sprintf(format, a...)
+	l.Loutput(1, Lpanic, s)
+	l.Flush()
+	panic(s)
+}
+
 func (l *Logger) Fatalf(format string, a ...any) {
 	l.Loutputf(1, Lfatal, format, a...)
-	l.Close()
+	l.exit()
+}
+
+func (l *Logger) Loutputw(calldepth int, level string, msg string, kv ...any) {
+	if l.enabled(ltoi(level)) {
+		l.output(1+calldepth, level, msg, kv)
+	}
+}
+
+func (l *Logger) Debugw(msg string, kv ...any) {
+	l.Loutputw(1, Ldebug, msg, kv...)
+}
+
+func (l *Logger) Tracew(msg string, kv ...any) {
+	l.Loutputw(1, Ltrace, msg, kv...)
+}
+
+func (l *Logger) Infow(msg string, kv ...any) {
+	l.Loutputw(1, Linfo, msg, kv...)
+}
+
+func (l *Logger) Warnw(msg string, kv ...any) {
+	l.Loutputw(1, Lwarn, msg, kv...)
+}
+
+func (l *Logger) Errorw(msg string, kv ...any) {
+	l.Loutputw(1, Lerror, msg, kv...)
+}
+
+func (l *Logger) Panicw(msg string, kv ...any) {
+	l.Loutputw(1, Lpanic, msg, kv...)
+	l.Flush()
+	panic(msg)
+}
+
+func (l *Logger) Fatalw(msg string, kv ...any) {
+	l.Loutputw(1, Lfatal, msg, kv...)
+	l.exit()
 }
@@ -79,6 +79,113 @@ func TestLoggerNew(t *testing.T) {
 	}
 }
 
+type memSink struct {
+	level   int
+	entries []Entry
+}
+
+func (s *memSink) Level() int           { return s.level }
+func (s *memSink) Write(e *Entry) error { s.entries = append(s.entries, *e); return nil }
+func (s *memSink) Flush() error         { return nil }
+func (s *memSink) Close() error         { return nil }
+
+func TestAddSink(t *testing.T) {
+	var buf bytes.Buffer
+	l := New("test: ", log.New(&buf, "", 0), "")
+	mem := &memSink{level: ltoi(Lwarn)}
+	l.AddSink("mem", mem)
+	if l.Sink("mem") != mem {
+		t.Errorf("logger sink should be added")
+	}
+
+	child := l.WithName("child: ")
+	child.Info("This is info")
+	child.Warn("This is warn")
+	if want, got := "INFO child: This is info\nWARN child: This is warn\n", buf.String(); want != got {
+		t.Errorf("logger output should match %q is %q", want, got)
+	}
+	if len(mem.entries) != 1 {
+		t.Fatalf("sink should have 1 entry, has %d", len(mem.entries))
+	}
+	if e := mem.entries[0]; e.Level != Lwarn || e.Name != "child: " || e.Message != "This is warn" {
+		t.Errorf("sink entry is %+v", e)
+	}
+}
+
+func TestWith(t *testing.T) {
+	var buf bytes.Buffer
+	l := New("test: ", log.New(&buf, "", 0), "")
+	child := l.With("user_id", 42).WithName("child: ")
+	child.Infow("This is info", "request_id", "a b", Any("ok", true))
+	l.Infoln("This is parent")
+	if want, got := "INFO child: This is info user_id=42 request_id=\"a b\" ok=true\nINFO test: This is parent\n", buf.String(); want != got {
+		t.Errorf("logger output should match %q is %q", want, got)
+	}
+
+	buf.Reset()
+	child.With("odd").Info("This is odd")
+	if want, got := "INFO child: This is odd user_id=42 !BADKEY=odd\n", buf.String(); want != got {
+		t.Errorf("logger output should match %q is %q", want, got)
+	}
+}
+
+func TestSetLevel(t *testing.T) {
+	var buf bytes.Buffer
+	l := New("test: ", log.New(&buf, "", 0), Linfo)
+	child := l.WithName("child: ")
+	child.Debug("Omitted debug")
+	if err := l.SetLevel("console", "debug"); err != nil {
+		t.Fatal(err)
+	}
+	child.Debug("This is debug")
+	if want, got := "DEBG child: This is debug\n", buf.String(); want != got {
+		t.Errorf("logger output should match %q is %q", want, got)
+	}
+	if want, got := "debug", child.Level("console"); want != got {
+		t.Errorf("logger level should match %q is %q", want, got)
+	}
+	if err := l.SetLevel("file", "debug"); err == nil {
+		t.Errorf("set level of missing sink should fail")
+	}
+	if err := l.SetLevel("console", "verbose"); err == nil {
+		t.Errorf("set unknown level should fail")
+	}
+	if want, got := "debug", child.Level("console"); want != got {
+		t.Errorf("unknown level should be ignored, level %q is %q", want, got)
+	}
+}
+
+func TestFatal(t *testing.T) {
+	var buf bytes.Buffer
+	l := New("test: ", log.New(&buf, "", 0), "")
+	code := -1
+	l.SetExitFunc(func(c int) { code = c })
+	l.Fatalf("This is %s", "fatal")
+	if code != 1 {
+		t.Errorf("fatal should exit with 1, is %d", code)
+	}
+	if want, got := "FATAL test: This is fatal\n", buf.String(); want != got {
+		t.Errorf("logger output should match %q is %q", want, got)
+	}
+	if l.Sink("console") != nil {
+		t.Errorf("fatal should close outputs")
+	}
+}
+
+func TestPanic(t *testing.T) {
+	var buf bytes.Buffer
+	l := New("test: ", log.New(&buf, "", 0), "")
+	defer func() {
+		if want, got := "This is panic\n", recover(); want != got {
+			t.Errorf("panic value should be %q is %q", want, got)
+		}
+		if want, got := "PANIC test: This is panic\n", buf.String(); want != got {
+			t.Errorf("logger output should match %q is %q", want, got)
+		}
+	}()
+	l.Panicln("This is panic")
+}
+
 func BenchmarkStdlogPrint(b *testing.B) {
 	const testString = "test"
 	var buf bytes.Buffer
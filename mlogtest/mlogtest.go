@@ -0,0 +1,222 @@
+// Package mlogtest provides loggers for testing what code logs.
+//
+// NewObserved returns a logger keeping the entries in memory, which can be
+// filtered and asserted:
+//
+//	l, logs := mlogtest.NewObserved("debug")
+//	run(l)
+//	mlogtest.AssertLogged(t, logs, "warn", "retry", "attempt", 2)
+//
+// NewTestLogger returns a logger writing through testing.T.Log, so the logs
+// appear under the failing test.
+package mlogtest
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ccpaging/mlog"
+)
+
+// Logs is the entries observed, safe for concurrent use.
+type Logs struct {
+	mu      sync.Mutex
+	entries []mlog.Entry
+}
+
+func (o *Logs) add(e mlog.Entry) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.entries = append(o.entries, e)
+}
+
+// Len returns the number of entries.
+func (o *Logs) Len() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return len(o.entries)
+}
+
+// All returns a copy of the entries in the order written.
+func (o *Logs) All() []mlog.Entry {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return append([]mlog.Entry(nil), o.entries...)
+}
+
+// TakeAll returns the entries and removes them.
+func (o *Logs) TakeAll() []mlog.Entry {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	entries := o.entries
+	o.entries = nil
+	return entries
+}
+
+// Filter returns the logs of the entries for which fn returns true.
+func (o *Logs) Filter(fn func(e mlog.Entry) bool) *Logs {
+	filtered := &Logs{}
+	for _, e := range o.All() {
+		if fn(e) {
+			filtered.entries = append(filtered.entries, e)
+		}
+	}
+	return filtered
+}
+
+// FilterLevel returns the logs of the entries at level, like "warn" or
+// mlog.Lwarn. An unknown level matches nothing.
+func (o *Logs) FilterLevel(level string) *Logs {
+	lv, err := mlog.ParseLevel(level)
+	return o.Filter(func(e mlog.Entry) bool {
+		return err == nil && e.Level == lv.Tag
+	})
+}
+
+// FilterMessage returns the logs of the entries with the message msg.
+func (o *Logs) FilterMessage(msg string) *Logs {
+	return o.Filter(func(e mlog.Entry) bool {
+		return strings.TrimRight(e.Message, "\n") == msg
+	})
+}
+
+// FilterMessageSnippet returns the logs of the entries whose message
+// contains snippet.
+func (o *Logs) FilterMessageSnippet(snippet string) *Logs {
+	return o.Filter(func(e mlog.Entry) bool {
+		return strings.Contains(e.Message, snippet)
+	})
+}
+
+// FilterField returns the logs of the entries with the field key equal to
+// value.
+func (o *Logs) FilterField(key string, value any) *Logs {
+	return o.Filter(func(e mlog.Entry) bool {
+		return hasField(e, key, value)
+	})
+}
+
+func hasField(e mlog.Entry, key string, value any) bool {
+	for _, f := range e.Fields {
+		if f.Key == key && reflect.DeepEqual(f.Value, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// ObserverSink keeps the entries at or above its level in Logs.
+type ObserverSink struct {
+	mlog.LevelVar
+	logs *Logs
+}
+
+// NewObserverSink returns the sink writing entries at or above level, and
+// the logs of them.
+func NewObserverSink(level string) (*ObserverSink, *Logs) {
+	s := &ObserverSink{logs: &Logs{}}
+	s.SetLevel(level)
+	return s, s.logs
+}
+
+func (s *ObserverSink) Write(e *mlog.Entry) error {
+	s.logs.add(*e)
+	return nil
+}
+
+func (s *ObserverSink) Flush() error { return nil }
+
+func (s *ObserverSink) Close() error { return nil }
+
+// NewObserved returns the logger whose only output is the observer sink
+// named "observer", at level, and the logs of it.
+func NewObserved(level string) (*mlog.Logger, *Logs) {
+	s, logs := NewObserverSink(level)
+	l := mlog.NewLogger("", &mlog.Settings{})
+	l.AddSink("observer", s)
+	return l, logs
+}
+
+// AssertLogged fails the test unless an entry at level, whose message
+// contains snippet, with the fields of the key-value pairs kv, is in logs.
+// An empty level or snippet matches any.
+func AssertLogged(t testing.TB, logs *Logs, level, snippet string, kv ...any) {
+	t.Helper()
+
+	matched := logs.FilterMessageSnippet(snippet)
+	if level != "" {
+		matched = matched.FilterLevel(level)
+	}
+	for i := 0; i < len(kv); i += 2 {
+		key := fmt.Sprint(kv[i])
+		var value any
+		if i+1 < len(kv) {
+			value = kv[i+1]
+		}
+		matched = matched.FilterField(key, value)
+	}
+	if matched.Len() > 0 {
+		return
+	}
+	var b strings.Builder
+	for _, e := range logs.All() {
+		fmt.Fprintf(&b, "\n\t%s%s%s %v", e.Level, e.Name, strings.TrimRight(e.Message, "\n"), e.Fields)
+	}
+	t.Errorf("no entry logged at %q with %q and %v, logged:%s", level, snippet, kv, b.String())
+}
+
+// TestSink writes entries at or above its level through testing.T.Log.
+// The entries written after the test completes are dropped.
+type TestSink struct {
+	mlog.LevelVar
+	t    testing.TB
+	enc  mlog.TextEncoder
+	mu   sync.Mutex
+	done bool
+	buf  []byte
+}
+
+// NewTestSink returns the sink writing entries at or above level to t.
+func NewTestSink(t testing.TB, level string) *TestSink {
+	s := &TestSink{t: t, enc: mlog.TextEncoder{Caller: mlog.CallerShort}}
+	s.SetLevel(level)
+	t.Cleanup(func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		s.done = true
+	})
+	return s
+}
+
+func (s *TestSink) Write(e *mlog.Entry) error {
+	s.t.Helper()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.done {
+		return nil
+	}
+	s.buf = s.enc.Encode(s.buf[:0], e)
+	s.t.Log(strings.TrimRight(string(s.buf), "\n"))
+	return nil
+}
+
+func (s *TestSink) Flush() error { return nil }
+
+func (s *TestSink) Close() error { return nil }
+
+// NewTestLogger returns the logger whose only output is the test sink named
+// "test", at level.
+func NewTestLogger(t testing.TB, level string) *mlog.Logger {
+	l := mlog.NewLogger("", &mlog.Settings{})
+	l.AddSink("test", NewTestSink(t, level))
+	return l
+}
@@ -0,0 +1,125 @@
+package mlogtest
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ccpaging/mlog"
+)
+
+func TestObserved(t *testing.T) {
+	l, logs := NewObserved("info")
+	l.Debug("This is debug")
+	l.Info("This is info")
+	l.With("user", "bob").Warnw("retry request", "attempt", 2)
+	l.ErrorErr(errors.New("boom"), "request failed")
+
+	if logs.Len() != 3 {
+		t.Fatalf("logs should have 3 entries, has %d", logs.Len())
+	}
+	if n := logs.FilterLevel("warn").Len(); n != 1 {
+		t.Errorf("logs should have 1 warn entry, has %d", n)
+	}
+	if n := logs.FilterLevel(mlog.Lerror).FilterMessage("request failed").Len(); n != 1 {
+		t.Errorf("logs should have 1 error entry, has %d", n)
+	}
+	if n := logs.FilterMessageSnippet("request").Len(); n != 2 {
+		t.Errorf("logs should have 2 request entries, has %d", n)
+	}
+	if n := logs.FilterField("user", "bob").FilterField("attempt", 2).Len(); n != 1 {
+		t.Errorf("logs should have 1 entry of bob, has %d", n)
+	}
+	if n := logs.FilterField("error.message", "boom").Len(); n != 1 {
+		t.Errorf("logs should have 1 entry of boom, has %d", n)
+	}
+	if n := logs.FilterLevel("unknown").Len(); n != 0 {
+		t.Errorf("logs should have no unknown entry, has %d", n)
+	}
+
+	AssertLogged(t, logs, "warn", "retry", "attempt", 2)
+	AssertLogged(t, logs, "", "This is info")
+
+	if entries := logs.TakeAll(); len(entries) != 3 || logs.Len() != 0 {
+		t.Errorf("logs should be taken, took %d, left %d", len(entries), logs.Len())
+	}
+}
+
+// fakeT records the failures of AssertLogged.
+type fakeT struct {
+	testing.TB
+	failed string
+}
+
+func (t *fakeT) Helper() {}
+
+func (t *fakeT) Errorf(format string, a ...any) {
+	t.failed = fmt.Sprintf(format, a...)
+}
+
+func TestAssertLoggedFails(t *testing.T) {
+	l, logs := NewObserved("debug")
+	l.Infow("This is info", "k", "v")
+
+	for _, c := range []struct {
+		level, snippet string
+		kv             []any
+	}{
+		{"warn", "This is info", nil},
+		{"info", "This is warn", nil},
+		{"info", "info", []any{"k", "w"}},
+		{"info", "info", []any{"missing", "v"}},
+	} {
+		ft := &fakeT{}
+		AssertLogged(ft, logs, c.level, c.snippet, c.kv...)
+		if !strings.Contains(ft.failed, "This is info") {
+			t.Errorf("assert %v should fail with the entries logged, is %q", c, ft.failed)
+		}
+	}
+}
+
+func TestTestLogger(t *testing.T) {
+	var sink *TestSink
+	t.Run("sub", func(t *testing.T) {
+		l := NewTestLogger(t, "debug")
+		sink = l.Sink("test").(*TestSink)
+		l.Infow("This is info", "k", "v")
+	})
+	// The sub test is completed, so the entry is dropped.
+	if err := sink.Write(&mlog.Entry{Level: mlog.Linfo, Message: "late"}); err != nil {
+		t.Errorf("late write should be dropped, %v", err)
+	}
+}
+
+// logT records the lines of Log, and the cleanups.
+type logT struct {
+	testing.TB
+	lines    []string
+	cleanups []func()
+}
+
+func (t *logT) Helper() {}
+
+func (t *logT) Log(a ...any) {
+	t.lines = append(t.lines, fmt.Sprint(a...))
+}
+
+func (t *logT) Cleanup(fn func()) {
+	t.cleanups = append(t.cleanups, fn)
+}
+
+func TestTestSinkLog(t *testing.T) {
+	lt := &logT{}
+	l := NewTestLogger(lt, "info")
+	l.Debug("This is debug")
+	l.Infow("This is info", "k", "v")
+	for _, fn := range lt.cleanups {
+		fn()
+	}
+	l.Info("This is late")
+
+	if want := []string{"mlogtest_test.go:116: INFO This is info k=v"}; len(lt.lines) != 1 || lt.lines[0] != want[0] {
+		t.Errorf("lines logged should be %q, are %q", want, lt.lines)
+	}
+}
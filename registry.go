@@ -0,0 +1,137 @@
+package mlog
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// The registry keeps the named loggers returned by Get, and the levels set
+// by SetNameLevel. The root logger is Default() with the name "".
+var registry struct {
+	mu      sync.Mutex
+	loggers map[string]*Logger
+	levels  map[string]int
+}
+
+func init() {
+	global.level = new(atomic.Int32)
+	global.level.Store(-1)
+	registry.loggers = map[string]*Logger{"": global}
+	registry.levels = make(map[string]int)
+}
+
+// parentName returns the name of the parent logger, like "db" for "db.pool".
+func parentName(name string) string {
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		return name[:i]
+	}
+	return ""
+}
+
+// joinName returns the dotted name of the child logger.
+func joinName(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	if name == "" {
+		return parent
+	}
+	return parent + "." + name
+}
+
+// resolveLevel returns the severity set for the name or its nearest
+// ancestor, or -1 if none. It must be called with registry.mu held.
+func resolveLevel(name string) int {
+	for {
+		if n, ok := registry.levels[name]; ok {
+			return n
+		}
+		if name == "" {
+			return -1
+		}
+		name = parentName(name)
+	}
+}
+
+// Get returns the logger named by the dotted name, like "db.pool". The
+// logger writes to the outputs of Default(), and is created once and cached.
+// Get("") returns Default().
+func Get(name string) *Logger {
+	name = strings.Trim(name, ".")
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	if l, ok := registry.loggers[name]; ok {
+		return l
+	}
+	l := global.WithName(name + " ")
+	l.path = name
+	l.level = new(atomic.Int32)
+	l.level.Store(int32(resolveLevel(name)))
+	registry.loggers[name] = l
+	return l
+}
+
+// Named returns the registered logger of the dotted name joined to the name
+// of l, like "db.pool" for Get("db").Named("pool").
+func (l *Logger) Named(name string) *Logger {
+	return Get(joinName(l.path, strings.Trim(name, ".")))
+}
+
+// SetNameLevel sets the level of the named logger and its descendants, which
+// is used for all outputs instead of their levels. The nearest ancestor
+// with a level is used, like "db" for "db.pool" if "db.pool" is not set.
+// The name "" sets the root. An empty level removes the setting of the name.
+func SetNameLevel(name, level string) error {
+	name = strings.Trim(name, ".")
+	n := -1
+	if level != "" {
+		var err error
+		if n, err = parseLevel(level); err != nil {
+			return err
+		}
+	}
+
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	if n < 0 {
+		delete(registry.levels, name)
+	} else {
+		registry.levels[name] = n
+	}
+	for path, l := range registry.loggers {
+		l.level.Store(int32(resolveLevel(path)))
+	}
+	return nil
+}
+
+// NameLevel returns the level name used by the named logger, like "debug",
+// or "" if the levels of outputs are used.
+func NameLevel(name string) string {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	if n := resolveLevel(strings.Trim(name, ".")); n >= 0 {
+		return levelOf(n).Name
+	}
+	return ""
+}
+
+// Loggers returns the sorted names of the registered loggers, except root.
+func Loggers() []string {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+
+	names := make([]string, 0, len(registry.loggers))
+	for name := range registry.loggers {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
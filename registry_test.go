@@ -0,0 +1,54 @@
+package mlog_test
+
+import (
+	"bytes"
+	stdlog "log"
+	"reflect"
+	"testing"
+
+	"github.com/ccpaging/mlog"
+)
+
+func TestRegistry(t *testing.T) {
+	mlog.ResetRegistry()
+	defer mlog.ResetRegistry()
+
+	var buf bytes.Buffer
+	mlog.Init(mlog.New("root ", stdlog.New(&buf, "", 0), mlog.Linfo))
+	defer mlog.Close()
+
+	pool := mlog.Get("db").Named("pool")
+	if pool != mlog.Get("db.pool") {
+		t.Errorf("named logger should be cached")
+	}
+	if want, got := []string{"db", "db.pool"}, mlog.Loggers(); !reflect.DeepEqual(want, got) {
+		t.Errorf("loggers should match %q is %q", want, got)
+	}
+
+	if err := mlog.SetNameLevel("db", "debug"); err != nil {
+		t.Fatal(err)
+	}
+	defer mlog.SetNameLevel("db", "")
+	if err := mlog.SetNameLevel("db", "verbose"); err == nil {
+		t.Errorf("unknown level should fail")
+	}
+
+	pool.Debug("This is pool debug")
+	mlog.Get("cache").Debug("Omitted cache debug")
+	mlog.Debug("Omitted root debug")
+	if want, got := "DEBG db.pool This is pool debug\n", buf.String(); want != got {
+		t.Errorf("logger output should match %q is %q", want, got)
+	}
+	if want, got := "debug", mlog.NameLevel("db.pool"); want != got {
+		t.Errorf("name level should match %q is %q", want, got)
+	}
+
+	buf.Reset()
+	mlog.SetNameLevel("db.pool", "error")
+	defer mlog.SetNameLevel("db.pool", "")
+	pool.Warn("Omitted pool warn")
+	mlog.Get("db").Debug("This is db debug")
+	if want, got := "DEBG db This is db debug\n", buf.String(); want != got {
+		t.Errorf("logger output should match %q is %q", want, got)
+	}
+}
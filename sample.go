@@ -0,0 +1,182 @@
+package mlog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+)
+
+// Sampling limits the repeated entries of a level. In every Interval, the
+// First entries with the same message are written, then every Thereafter-th
+// entry; the others are sampled away. Zero Thereafter writes none of them.
+type Sampling struct {
+	Interval   time.Duration
+	First      int
+	Thereafter int
+}
+
+// UnmarshalJSON decodes the sampling with Interval as a duration string,
+// like "1s" or "1d", or a number of nanoseconds.
+func (c *Sampling) UnmarshalJSON(data []byte) error {
+	type sampling Sampling
+	var v struct {
+		sampling
+		Interval json.RawMessage
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&v); err != nil {
+		return err
+	}
+	*c = Sampling(v.sampling)
+	if len(v.Interval) == 0 || string(v.Interval) == "null" {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(v.Interval, &s); err != nil {
+		return json.Unmarshal(v.Interval, &c.Interval)
+	}
+	d, err := strToDuration(s)
+	if err != nil {
+		return fmt.Errorf("Interval: bad duration %q", s)
+	}
+	c.Interval = d
+	return nil
+}
+
+// sampleBuckets is the number of message counters of a level. Messages
+// with the same hash share a counter.
+const sampleBuckets = 1024
+
+type levelSampler struct {
+	Sampling
+	start   time.Time
+	counts  [sampleBuckets]int
+	dropped int
+	timer   *time.Timer // writes the summary when the interval passed
+}
+
+// sampler counts entries by level and message.
+type sampler struct {
+	mu     sync.Mutex
+	levels map[int]*levelSampler
+	core   *core // the outputs the summaries are written to
+}
+
+func (s *sampler) set(n int, cfg Sampling) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if ls := s.levels[n]; ls != nil && ls.timer != nil {
+		ls.timer.Stop()
+	}
+	if cfg.Interval <= 0 {
+		delete(s.levels, n)
+		return
+	}
+	s.levels[n] = &levelSampler{Sampling: cfg}
+}
+
+// summary returns the entry telling how many entries of the severity n
+// were sampled away, and starts a new interval.
+func (ls *levelSampler) summary(n int, now time.Time) *Entry {
+	var e *Entry
+	if ls.dropped > 0 {
+		e = &Entry{
+			Time:    now,
+			Level:   levelOf(n).Tag,
+			Message: "mlog: entries sampled away",
+			Fields:  []Field{{"sampled", ls.dropped}, {"interval", ls.Interval.String()}},
+		}
+	}
+	ls.start = now
+	ls.counts = [sampleBuckets]int{}
+	ls.dropped = 0
+	if ls.timer != nil {
+		ls.timer.Stop()
+		ls.timer = nil
+	}
+	return e
+}
+
+// sample reports whether the entries of the severity n and message msg
+// should be written. The summary of the previous interval is returned, if
+// entries were sampled away in it.
+func (s *sampler) sample(n int, msg string, now time.Time) (ok bool, summary *Entry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ls := s.levels[n]
+	if ls == nil {
+		return true, nil
+	}
+	if now.Sub(ls.start) >= ls.Interval {
+		summary = ls.summary(n, now)
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(msg))
+	i := h.Sum32() % sampleBuckets
+	ls.counts[i]++
+	c := ls.counts[i]
+	if c <= ls.First || (ls.Thereafter > 0 && (c-ls.First)%ls.Thereafter == 0) {
+		return true, summary
+	}
+	ls.dropped++
+	if ls.timer == nil {
+		ls.timer = time.AfterFunc(time.Until(ls.start.Add(ls.Interval)), func() {
+			s.expire(n, ls)
+		})
+	}
+	return false, summary
+}
+
+// expire writes the summary of the level sampler ls of the severity n, if
+// its interval passed and no entry came since to start a new one.
+func (s *sampler) expire(n int, ls *levelSampler) {
+	s.mu.Lock()
+	now := time.Now()
+	if s.levels[n] != ls || now.Sub(ls.start) < ls.Interval {
+		s.mu.Unlock()
+		return
+	}
+	e := ls.summary(n, now)
+	s.mu.Unlock()
+
+	if e != nil && s.core != nil {
+		s.core.mu.RLock()
+		s.core.write(e, n, -1)
+		s.core.mu.RUnlock()
+	}
+}
+
+// flush returns the summaries of all levels, and starts new intervals.
+func (s *sampler) flush(now time.Time) []*Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var summaries []*Entry
+	for n, ls := range s.levels {
+		if e := ls.summary(n, now); e != nil {
+			summaries = append(summaries, e)
+		}
+	}
+	return summaries
+}
+
+// SetSampling limits the repeated entries of the level for the logger and all
+// loggers sharing its outputs. A zero Interval disables sampling of the level.
+// The summaries of entries sampled away are written at the level once the
+// interval passed, and on Flush or Close.
+func (l *Logger) SetSampling(level string, cfg Sampling) {
+	l.core.mu.Lock()
+	defer l.core.mu.Unlock()
+
+	if l.core.sampler == nil {
+		l.core.sampler = &sampler{levels: make(map[int]*levelSampler), core: l.core}
+	}
+	l.core.sampler.set(ltoi(level), cfg)
+}
@@ -0,0 +1,72 @@
+package mlog
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSampling(t *testing.T) {
+	mem := &memSink{}
+	l := NewLogger("sample: ", &Settings{
+		Sampling: map[string]Sampling{"info": {Interval: time.Hour, First: 2, Thereafter: 3}},
+	})
+	l.AddSink("mem", mem)
+
+	for i := 0; i < 10; i++ {
+		l.Info("hot loop")
+		l.Debug("not sampled")
+	}
+	l.Warn("hot loop")
+
+	var info, debug, warn int
+	for _, e := range mem.entries {
+		switch e.Level {
+		case Linfo:
+			info++
+		case Ldebug:
+			debug++
+		case Lwarn:
+			warn++
+		}
+	}
+	if info != 4 || debug != 10 || warn != 1 {
+		t.Errorf("sampled entries should be 4, 10, 1, are %d, %d, %d", info, debug, warn)
+	}
+
+	l.Flush()
+	e := mem.entries[len(mem.entries)-1]
+	if e.Level != Linfo || len(e.Fields) == 0 || e.Fields[0] != (Field{"sampled", 6}) {
+		t.Errorf("summary should have 6 sampled, is %+v", e)
+	}
+}
+
+// chanSink sends the entries written to a channel.
+type chanSink chan Entry
+
+func (s chanSink) Level() int           { return 0 }
+func (s chanSink) Write(e *Entry) error { s <- *e; return nil }
+func (s chanSink) Flush() error         { return nil }
+func (s chanSink) Close() error         { return nil }
+
+func TestSamplingTimer(t *testing.T) {
+	ch := make(chanSink, 10)
+	l := NewLogger("sample: ", &Settings{
+		Sampling: map[string]Sampling{"info": {Interval: 20 * time.Millisecond, First: 1}},
+	})
+	l.AddSink("chan", ch)
+
+	for i := 0; i < 3; i++ {
+		l.Info("hot loop")
+	}
+	<-ch
+	// No entry comes after the interval, the summary is written anyway.
+	select {
+	case e := <-ch:
+		if len(e.Fields) == 0 || e.Fields[0] != (Field{"sampled", 2}) {
+			t.Errorf("summary should have 2 sampled, is %+v", e)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("summary should be written once the interval passed")
+	}
+	l.Close()
+}
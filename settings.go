@@ -0,0 +1,219 @@
+package mlog
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/ccpaging/mlog/file"
+)
+
+// EnvPrefix is the prefix of environment variables overriding settings,
+// like MLOG_CONSOLE_LEVEL for ConsoleLevel.
+const EnvPrefix = "MLOG_"
+
+// LoadSettings reads the settings from the file at path, overridden by the
+// environment variables, and validates them. The fields not in the file are
+// the same as DefaultSettings.
+//
+// A file with ".json" extension, or starting with '{', is read as JSON
+// object of the fields. Otherwise it is read as lines of key=value, where
+// key is the field name in any case, with or without '_', like
+//
+//	# comment
+//	console_level = warn
+//	file_location = /var/log/app.log
+func LoadSettings(path string) (*Settings, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	s := DefaultSettings()
+	if strings.EqualFold(filepath.Ext(path), ".json") || bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(s); err != nil {
+			return nil, fmt.Errorf("mlog: %s: %w", path, err)
+		}
+	} else if err := s.parseKeyValues(data); err != nil {
+		return nil, fmt.Errorf("mlog: %s: %w", path, err)
+	}
+	if err := s.LoadEnv(); err != nil {
+		return nil, err
+	}
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Settings) parseKeyValues(data []byte) error {
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return fmt.Errorf("line %d: missing '=' in %q", n, line)
+		}
+		value = strings.TrimSpace(value)
+		if uq, err := strconv.Unquote(value); err == nil {
+			value = uq
+		}
+		if err := s.set(key, value); err != nil {
+			return fmt.Errorf("line %d: %w", n, err)
+		}
+	}
+	return sc.Err()
+}
+
+// LoadEnv overrides the settings by the environment variables with
+// EnvPrefix, like MLOG_FILE_LOCATION=/var/log/app.log. The variables not
+// matching any field are ignored.
+func (s *Settings) LoadEnv() error {
+	for _, kv := range os.Environ() {
+		if !strings.HasPrefix(kv, EnvPrefix) {
+			continue
+		}
+		key, value, _ := strings.Cut(kv[len(EnvPrefix):], "=")
+		if settingsField(key) < 0 {
+			continue
+		}
+		if err := s.set(key, value); err != nil {
+			return fmt.Errorf("mlog: %s%s: %w", EnvPrefix, key, err)
+		}
+	}
+	return nil
+}
+
+// normalizeKey returns key in lower case without '_', '-' and '.'.
+func normalizeKey(key string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '_', '-', '.', ' ', '\t':
+			return -1
+		}
+		return r
+	}, strings.ToLower(key))
+}
+
+// settingsField returns the index of the Settings field named by key, or -1.
+func settingsField(key string) int {
+	key = normalizeKey(key)
+	t := reflect.TypeOf(Settings{})
+	for i := 0; i < t.NumField(); i++ {
+		if strings.ToLower(t.Field(i).Name) == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// set sets the field named by key from the text value.
+func (s *Settings) set(key, value string) error {
+	i := settingsField(key)
+	if i < 0 {
+		return errors.New("unknown setting " + strconv.Quote(strings.TrimSpace(key)))
+	}
+	f := reflect.ValueOf(s).Elem().Field(i)
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		f.SetBool(b)
+	case reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		f.SetInt(int64(n))
+	default:
+		return errors.New("setting " + strconv.Quote(strings.TrimSpace(key)) + " is only supported in JSON")
+	}
+	return nil
+}
+
+// Validate returns the error of the first bad value in the settings, like
+// an unknown level or an unparsable FileLimitSize.
+func (s *Settings) Validate() error {
+	for _, v := range []struct{ name, level string }{
+		{"ConsoleLevel", s.ConsoleLevel},
+		{"FileLevel", s.FileLevel},
+		{"SyslogLevel", s.SyslogLevel},
+		{"StacktraceLevel", s.StacktraceLevel},
+	} {
+		if v.level == "" {
+			continue
+		}
+		if _, err := parseLevel(v.level); err != nil {
+			return fmt.Errorf("mlog: %s: %w", v.name, err)
+		}
+	}
+	for _, v := range []struct{ name, format string }{
+		{"ConsoleFormat", s.ConsoleFormat},
+		{"FileFormat", s.FileFormat},
+	} {
+		switch strings.ToLower(strings.TrimSpace(v.format)) {
+		case "", "text", "json", "logfmt":
+		default:
+			return fmt.Errorf("mlog: %s: unknown format %q", v.name, v.format)
+		}
+	}
+	if s.FileLimitSize != "" {
+		if n, err := strToNumSuffix(s.FileLimitSize, 1024); err != nil || n < 0 {
+			return fmt.Errorf("mlog: FileLimitSize: bad size %q", s.FileLimitSize)
+		}
+	}
+	if s.FileMaxTotalSize != "" {
+		if n, err := strToNumSuffix(s.FileMaxTotalSize, 1024); err != nil || n < 0 {
+			return fmt.Errorf("mlog: FileMaxTotalSize: bad size %q", s.FileMaxTotalSize)
+		}
+	}
+	if s.FileMaxAge != "" {
+		if d, err := strToDuration(s.FileMaxAge); err != nil || d < 0 {
+			return fmt.Errorf("mlog: FileMaxAge: bad duration %q", s.FileMaxAge)
+		}
+	}
+	if s.FileFlushInterval != "" {
+		if d, err := strToDuration(s.FileFlushInterval); err != nil || d < 0 {
+			return fmt.Errorf("mlog: FileFlushInterval: bad duration %q", s.FileFlushInterval)
+		}
+	}
+	if _, err := file.ParseRotation(s.FileRotation); err != nil {
+		return fmt.Errorf("mlog: FileRotation: %w", err)
+	}
+	if _, err := parseCaller(s.Caller); err != nil {
+		return fmt.Errorf("mlog: Caller: %w", err)
+	}
+	if _, err := parseFacility(s.SyslogFacility); err != nil {
+		return fmt.Errorf("mlog: SyslogFacility: %w", err)
+	}
+	if s.FileBackupCount < 0 {
+		return fmt.Errorf("mlog: FileBackupCount: negative count %d", s.FileBackupCount)
+	}
+	if s.AsyncQueueSize < 0 {
+		return fmt.Errorf("mlog: AsyncQueueSize: negative size %d", s.AsyncQueueSize)
+	}
+	for level, cfg := range s.Sampling {
+		if _, err := parseLevel(level); err != nil {
+			return fmt.Errorf("mlog: Sampling: %w", err)
+		}
+		if cfg.First < 0 || cfg.Thereafter < 0 {
+			return fmt.Errorf("mlog: Sampling: negative count of %q", level)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package mlog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeTemp(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadSettings(t *testing.T) {
+	path := writeTemp(t, "log.json", `{"ConsoleLevel": "warn", "EnableFile": true, "FileLimitSize": "2M"}`)
+	t.Setenv("MLOG_FILE_LOCATION", "/var/log/app.log")
+	t.Setenv("MLOG_UNRELATED", "ignored")
+
+	s, err := LoadSettings(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.ConsoleLevel != "warn" || !s.EnableFile || s.FileLimitSize != "2M" || s.FileLocation != "/var/log/app.log" || s.FileBackupCount != 7 {
+		t.Errorf("settings are %+v", s)
+	}
+
+	path = writeTemp(t, "log.conf", "# comment\nconsole_level = error\nFILE-BACKUP-COUNT=3\nconsole_format=\"logfmt\"\n")
+	t.Setenv("MLOG_CONSOLE_LEVEL", "debug")
+	if s, err = LoadSettings(path); err != nil {
+		t.Fatal(err)
+	}
+	if s.ConsoleLevel != "debug" || s.FileBackupCount != 3 || s.ConsoleFormat != "logfmt" {
+		t.Errorf("settings are %+v", s)
+	}
+}
+
+func TestLoadSettingsSampling(t *testing.T) {
+	path := writeTemp(t, "log.json", `{"Sampling": {"debug": {"Interval": "1s", "First": 10, "Thereafter": 100}, "info": {"Interval": 60000000000}}}`)
+	s, err := LoadSettings(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, got := (Sampling{time.Second, 10, 100}), s.Sampling["debug"]; want != got {
+		t.Errorf("debug sampling should be %+v, is %+v", want, got)
+	}
+	if want, got := (Sampling{Interval: time.Minute}), s.Sampling["info"]; want != got {
+		t.Errorf("info sampling should be %+v, is %+v", want, got)
+	}
+}
+
+func TestLoadSettingsErrors(t *testing.T) {
+	for _, tc := range []struct{ name, content, want string }{
+		{"size.conf", "file_limit_size=10X", "FileLimitSize"},
+		{"level.json", `{"FileLevel": "verbose"}`, "unknown level"},
+		{"unknown.conf", "colour=true", "unknown setting"},
+		{"unknown.json", `{"Colour": true}`, "unknown field"},
+		{"bool.conf", "enable_file=maybe", "enable_file"},
+		{"format.conf", "file_format=xml", "unknown format"},
+		{"age.conf", "file_max_age=month", "FileMaxAge"},
+		{"total.conf", "file_max_total_size=1X", "FileMaxTotalSize"},
+		{"flush.conf", "file_flush_interval=-1s", "FileFlushInterval"},
+		{"interval.json", `{"Sampling": {"debug": {"Interval": "often"}}}`, "Interval"},
+		{"sampling.json", `{"Sampling": {"debug": {"Every": 2}}}`, "unknown field"},
+	} {
+		_, err := LoadSettings(writeTemp(t, tc.name, tc.content))
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: error should contain %q, is %v", tc.name, tc.want, err)
+		}
+	}
+
+	t.Setenv("MLOG_ASYNC", "yes please")
+	if _, err := LoadSettings(writeTemp(t, "ok.conf", "")); err == nil || !strings.Contains(err.Error(), "MLOG_ASYNC") {
+		t.Errorf("bad environment should fail, is %v", err)
+	}
+}
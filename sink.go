@@ -0,0 +1,197 @@
+package mlog
+
+import (
+	"io"
+	stdlog "log"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ccpaging/mlog/file"
+)
+
+// Entry is a single log record passed from Logger to every Sink.
+type Entry struct {
+	Time    time.Time
+	Level   string // level prefix, like Linfo
+	Name    string // logger name
+	Message string
+	Fields  []Field
+	PC      uintptr   // program counter of the caller, 0 if unknown
+	Stack   []uintptr // program counters of the stack trace, if captured
+}
+
+// Frame returns the caller of the entry.
+func (e *Entry) Frame() runtime.Frame {
+	if e.PC == 0 {
+		return runtime.Frame{}
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{e.PC}).Next()
+	return frame
+}
+
+// Sink is an output of Logger, like console, file, syslog.
+type Sink interface {
+	// Level returns the severity of the lowest entry written.
+	Level() int
+	// Write writes the entry to the output.
+	Write(e *Entry) error
+	// Flush writes any buffered data to the underlying output.
+	Flush() error
+	// Close flushes and closes the output.
+	Close() error
+}
+
+// LevelVar is the level of a sink which can be changed at runtime.
+// The zero value is Ldebug, the lowest severity.
+type LevelVar struct {
+	n atomic.Int32
+}
+
+// Level returns the severity.
+func (v *LevelVar) Level() int {
+	return int(v.n.Load())
+}
+
+// SetLevel changes the level, like "debug" or Ldebug.
+func (v *LevelVar) SetLevel(level string) {
+	v.n.Store(int32(ltoi(level)))
+}
+
+// LevelSetter is implemented by sinks whose level can be changed.
+type LevelSetter interface {
+	SetLevel(level string)
+}
+
+// WriterSink writes entries to io.Writer, one line per entry.
+type WriterSink struct {
+	LevelVar
+	mu     sync.Mutex
+	w      io.Writer
+	closer io.Closer
+	enc    Encoder
+	color  bool // console color of levels
+	buf    []byte
+	cbuf   []byte
+}
+
+// NewWriterSink returns a sink which writes entries at or above level to w
+// as std log text lines. The flag argument is the same as std log's, like
+// LstdFlags.
+func NewWriterSink(w io.Writer, level string, flag int) *WriterSink {
+	return NewEncoderSink(w, level, &TextEncoder{Flag: flag})
+}
+
+// NewEncoderSink returns a sink which writes entries at or above level to w
+// in the format of enc.
+func NewEncoderSink(w io.Writer, level string, enc Encoder) *WriterSink {
+	s := &WriterSink{
+		w:   w,
+		enc: enc,
+	}
+	s.SetLevel(level)
+	return s
+}
+
+func newFileSink(fw *file.File, level string, enc Encoder) *WriterSink {
+	s := NewEncoderSink(fw, level, enc)
+	s.closer = fw
+	return s
+}
+
+func (s *WriterSink) Write(e *Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.buf = s.enc.Encode(s.buf[:0], e)
+	if s.color {
+		s.cbuf = appendColor(s.cbuf[:0], e.Level, s.buf)
+		_, err := s.w.Write(s.cbuf)
+		return err
+	}
+	_, err := s.w.Write(s.buf)
+	return err
+}
+
+func (s *WriterSink) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if f, ok := s.w.(interface{ Flush() error }); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+func (s *WriterSink) Close() error {
+	if err := s.Flush(); err != nil {
+		return err
+	}
+	if s.closer != nil {
+		return s.closer.Close()
+	}
+	return nil
+}
+
+// stdLogSink writes entries to std log with its prefix, flags and writer.
+type stdLogSink struct {
+	LevelVar
+	mu  sync.Mutex
+	log *stdlog.Logger
+	buf []byte
+}
+
+func newStdLogSink(l *stdlog.Logger, level string) *stdLogSink {
+	s := &stdLogSink{log: l}
+	s.SetLevel(level)
+	return s
+}
+
+func (s *stdLogSink) Write(e *Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	enc := TextEncoder{Prefix: s.log.Prefix(), Flag: s.log.Flags()}
+	s.buf = enc.Encode(s.buf[:0], e)
+	_, err := s.log.Writer().Write(s.buf)
+	return err
+}
+
+func (s *stdLogSink) Flush() error { return nil }
+
+func (s *stdLogSink) Close() error { return nil }
+
+// levelWriter is an io.Writer which writes every line to logger at level.
+type levelWriter struct {
+	l     *Logger
+	level string
+	name  string
+}
+
+func (w *levelWriter) Write(b []byte) (int, error) {
+	w.l.emit(&Entry{
+		Time:    time.Now(),
+		Level:   w.level,
+		Name:    w.name,
+		Message: string(b),
+		Fields:  w.l.fields,
+		PC:      stdLogCaller(),
+	})
+	return len(b), nil
+}
+
+// stdLogCaller returns the program counter of the caller of std log, which
+// called levelWriter.Write, or 0 if not found.
+func stdLogCaller() uintptr {
+	var pcs [8]uintptr
+	n := runtime.Callers(3, pcs[:])
+	for _, pc := range pcs[:n] {
+		frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+		if !strings.HasPrefix(frame.Function, "log.") {
+			return pc
+		}
+	}
+	return 0
+}
@@ -0,0 +1,167 @@
+package mlog
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+)
+
+// slogAnchors maps the severities of the built-in levels to slog levels.
+// The other severities are interpolated, like slog.LevelInfo+2 for
+// SeverityInfo+5.
+var slogAnchors = []struct {
+	severity int
+	level    slog.Level
+}{
+	{SeverityDebug, slog.LevelDebug},
+	{SeverityTrace, slog.LevelDebug + 2},
+	{SeverityInfo, slog.LevelInfo},
+	{SeverityWarn, slog.LevelWarn},
+	{SeverityError, slog.LevelError},
+	{SeverityPanic, slog.LevelError + 2},
+	{SeverityFatal, slog.LevelError + 4},
+}
+
+// severityToSlog returns the slog level of the severity n.
+func severityToSlog(n int) slog.Level {
+	last := len(slogAnchors) - 1
+	for i := 0; i < last; i++ {
+		a, b := slogAnchors[i], slogAnchors[i+1]
+		if n < b.severity {
+			return a.level + slog.Level((n-a.severity)*int(b.level-a.level)/(b.severity-a.severity))
+		}
+	}
+	return slogAnchors[last].level + slog.Level((n-slogAnchors[last].severity)/5)
+}
+
+// SlogLevel returns the slog level of the mlog level.
+func SlogLevel(level string) slog.Level {
+	return severityToSlog(ltoi(level))
+}
+
+// LevelFromSlog returns the mlog level of the slog level, the highest level
+// not above it.
+func LevelFromSlog(level slog.Level) string {
+	list := *levels.Load()
+	tag := list[0].Tag
+	for _, l := range list {
+		if severityToSlog(l.Severity) > level {
+			break
+		}
+		tag = l.Tag
+	}
+	return tag
+}
+
+// trimName returns the logger name without the trailing separators,
+// like "main" for "main: ".
+func trimName(name string) string {
+	return strings.TrimRight(name, ": ")
+}
+
+// appendAttrs appends the attrs to fs as fields. The keys of group
+// are joined with dot.
+func appendAttrs(fs []Field, group string, attrs ...slog.Attr) []Field {
+	for _, a := range attrs {
+		v := a.Value.Resolve()
+		key := a.Key
+		if group != "" && key != "" {
+			key = group + "." + key
+		} else if key == "" {
+			key = group
+		}
+		if v.Kind() == slog.KindGroup {
+			fs = appendAttrs(fs, key, v.Group()...)
+			continue
+		}
+		if a.Key == "" {
+			continue
+		}
+		fs = append(fs, Field{key, v.Any()})
+	}
+	return fs
+}
+
+// SlogHandler is a slog.Handler which writes records to Logger.
+type SlogHandler struct {
+	l     *Logger
+	group string
+}
+
+// NewSlogHandler returns a slog.Handler writing to the outputs of l
+// with its name, fields and levels.
+func NewSlogHandler(l *Logger) *SlogHandler {
+	return &SlogHandler{l: l}
+}
+
+func (h *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.l.enabled(ltoi(LevelFromSlog(level)))
+}
+
+func (h *SlogHandler) Handle(ctx context.Context, r slog.Record) error {
+	fields := appendFields(h.l.fields[:len(h.l.fields):len(h.l.fields)], appendContext(ctx, nil))
+	r.Attrs(func(a slog.Attr) bool {
+		fields = appendAttrs(fields, h.group, a)
+		return true
+	})
+	h.l.emit(&Entry{
+		Time:    r.Time,
+		Level:   LevelFromSlog(r.Level),
+		Name:    h.l.name,
+		Message: r.Message,
+		Fields:  fields,
+		PC:      r.PC,
+	})
+	return nil
+}
+
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	l := h.l.With()
+	l.fields = appendAttrs(l.fields, h.group, attrs...)
+	return &SlogHandler{l: l, group: h.group}
+}
+
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	group := name
+	if h.group != "" {
+		group = h.group + "." + name
+	}
+	return &SlogHandler{l: h.l, group: group}
+}
+
+// slogSink is an output which forwards entries to slog.Handler.
+type slogSink struct {
+	LevelVar
+	h slog.Handler
+}
+
+// NewSlogSink returns a sink which forwards entries at or above level to h.
+// The logger name is added as the attribute "logger".
+func NewSlogSink(h slog.Handler, level string) Sink {
+	s := &slogSink{h: h}
+	s.SetLevel(level)
+	return s
+}
+
+func (s *slogSink) Write(e *Entry) error {
+	ctx := context.Background()
+	level := SlogLevel(e.Level)
+	if !s.h.Enabled(ctx, level) {
+		return nil
+	}
+	r := slog.NewRecord(e.Time, level, strings.TrimRight(e.Message, "\r\n"), e.PC)
+	if name := trimName(e.Name); name != "" {
+		r.AddAttrs(slog.String("logger", name))
+	}
+	for _, f := range e.Fields {
+		r.AddAttrs(slog.Any(f.Key, f.Value))
+	}
+	return s.h.Handle(ctx, r)
+}
+
+func (s *slogSink) Flush() error { return nil }
+
+func (s *slogSink) Close() error { return nil }
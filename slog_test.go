@@ -0,0 +1,51 @@
+package mlog
+
+import (
+	"bytes"
+	"log"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestSlogHandler(t *testing.T) {
+	var buf bytes.Buffer
+	l := New("slog: ", log.New(&buf, "", 0), Linfo)
+	sl := slog.New(NewSlogHandler(l)).With("user_id", 42).WithGroup("req")
+
+	sl.Debug("This is debug")
+	sl.Warn("This is warn", "id", "a1", slog.Group("peer", "port", 80))
+	if want, got := "WARN slog: This is warn user_id=42 req.id=a1 req.peer.port=80\n", buf.String(); want != got {
+		t.Errorf("slog output should match %q is %q", want, got)
+	}
+}
+
+func TestSlogSink(t *testing.T) {
+	var buf bytes.Buffer
+	h := slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey && len(groups) == 0 {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+	l := NewLogger("sink: ", &Settings{})
+	l.AddSink("slog", NewSlogSink(h, Ltrace))
+
+	l.Debug("This is debug")
+	l.With("k", "v").Errorln("This is error")
+	if want, got := "level=ERROR msg=\"This is error\" logger=sink k=v\n", buf.String(); want != got {
+		t.Errorf("slog sink output should match %q is %q", want, got)
+	}
+}
+
+func TestSlogLevel(t *testing.T) {
+	for _, lv := range *levels.Load() {
+		level := lv.Tag
+		if got := LevelFromSlog(SlogLevel(level)); got != level {
+			t.Errorf("level %q should map back, is %q", strings.TrimSpace(level), got)
+		}
+	}
+}
@@ -1,7 +1,6 @@
 package mlog
 
 import (
-	"io"
 	"log"
 )
 
@@ -11,16 +10,9 @@ func (l *Logger) StdLog(name string) *log.Logger {
 
 // StdLogAt returns *log.Logger which writes to supplied zap logger at required level.
 func (l *Logger) StdLogAt(level, name string) *log.Logger {
-	l.mu.Lock()
-	defer l.mu.Unlock()
-
-	n := ltoi(level)
-	prefix := levelStrings[n] + name
-	if w := l.levelWriter(n); w != nil {
-		return log.New(w, prefix, LstdFlags)
-	}
-
-	return log.New(io.Discard, prefix, LstdFlags)
+	// The time and prefix are added by the outputs of logger, and the
+	// level is checked on every write, as it may be changed at runtime.
+	return log.New(&levelWriter{l: l, level: levelOf(ltoi(level)).Tag, name: name}, "", 0)
 }
 
 // NewStdLog returns a *log.Logger which writes to the supplied zap Logger at
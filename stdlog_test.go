@@ -27,3 +27,13 @@ func TestStdLogAt(t *testing.T) {
 		t.Errorf("\nwant empty\ngot: %q", buf.String())
 	}
 }
+
+func TestStdLogAtCaller(t *testing.T) {
+	var buf bytes.Buffer
+	l := log.New("", stdlog.New(&buf, "", stdlog.Lshortfile), log.Linfo)
+
+	l.StdLogAt("info", "s: ").Print("hello")
+	if want, got := "stdlog_test.go:35: INFO s: hello\n", buf.String(); want != got {
+		t.Errorf("\nwant: %q\ngot:  %q", want, got)
+	}
+}
@@ -114,6 +114,13 @@ func (w *Writer) Write(b []byte) (int, error) {
 	return w.writeAndRetry(w.priority, string(b))
 }
 
+// WritePriority sends a log message with the severity of p to the syslog
+// daemon, ignoring the severity passed to Dial. The facility passed to Dial
+// is used.
+func (w *Writer) WritePriority(p Priority, msg string) (int, error) {
+	return w.writeAndRetry(p, msg)
+}
+
 // Close closes a connection to the syslog daemon.
 func (w *Writer) Close() error {
 	w.mu.Lock()
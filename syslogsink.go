@@ -0,0 +1,93 @@
+//go:build !plan9
+
+package mlog
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ccpaging/mlog/syslog"
+)
+
+var syslogFacilities = map[string]syslog.Priority{
+	"kern":     syslog.LOG_KERN,
+	"user":     syslog.LOG_USER,
+	"mail":     syslog.LOG_MAIL,
+	"daemon":   syslog.LOG_DAEMON,
+	"auth":     syslog.LOG_AUTH,
+	"syslog":   syslog.LOG_SYSLOG,
+	"lpr":      syslog.LOG_LPR,
+	"news":     syslog.LOG_NEWS,
+	"uucp":     syslog.LOG_UUCP,
+	"cron":     syslog.LOG_CRON,
+	"authpriv": syslog.LOG_AUTHPRIV,
+	"ftp":      syslog.LOG_FTP,
+	"local0":   syslog.LOG_LOCAL0,
+	"local1":   syslog.LOG_LOCAL1,
+	"local2":   syslog.LOG_LOCAL2,
+	"local3":   syslog.LOG_LOCAL3,
+	"local4":   syslog.LOG_LOCAL4,
+	"local5":   syslog.LOG_LOCAL5,
+	"local6":   syslog.LOG_LOCAL6,
+	"local7":   syslog.LOG_LOCAL7,
+}
+
+// parseFacility returns the syslog facility by name, like "local0".
+// The empty name is "user".
+func parseFacility(name string) (syslog.Priority, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return syslog.LOG_USER, nil
+	}
+	if p, ok := syslogFacilities[strings.TrimPrefix(name, "log_")]; ok {
+		return p, nil
+	}
+	return 0, errors.New("mlog: unknown syslog facility " + strconv.Quote(name))
+}
+
+// SyslogSink writes entries to syslog, with the syslog severity of entry
+// level, see Level.
+type SyslogSink struct {
+	LevelVar
+	mu  sync.Mutex
+	w   *syslog.Writer
+	enc TextEncoder
+	buf []byte
+}
+
+// NewSyslogSink returns a sink which writes entries at or above level to w.
+// The time is not written, as it is added by syslog.
+func NewSyslogSink(w *syslog.Writer, level string) *SyslogSink {
+	s := &SyslogSink{w: w}
+	s.SetLevel(level)
+	return s
+}
+
+func newSyslogSink(s *Settings) (Sink, error) {
+	facility, err := parseFacility(s.SyslogFacility)
+	if err != nil {
+		return nil, err
+	}
+	w, err := syslog.Dial(s.SyslogNetwork, s.SyslogAddress, facility|syslog.LOG_INFO, s.SyslogTag)
+	if err != nil {
+		return nil, err
+	}
+	return NewSyslogSink(w, s.SyslogLevel), nil
+}
+
+func (s *SyslogSink) Write(e *Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.buf = s.enc.Encode(s.buf[:0], e)
+	_, err := s.w.WritePriority(syslog.Priority(levelOf(ltoi(e.Level)).Syslog), string(s.buf))
+	return err
+}
+
+func (s *SyslogSink) Flush() error { return nil }
+
+func (s *SyslogSink) Close() error {
+	return s.w.Close()
+}
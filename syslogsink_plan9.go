@@ -0,0 +1,11 @@
+package mlog
+
+import "errors"
+
+func parseFacility(name string) (int, error) {
+	return 0, nil
+}
+
+func newSyslogSink(s *Settings) (Sink, error) {
+	return nil, errors.New("mlog: syslog is not supported on plan9")
+}
@@ -0,0 +1,51 @@
+//go:build !plan9
+
+package mlog
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSyslogSink(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip("udp is not available:", err)
+	}
+	defer pc.Close()
+
+	l := NewLogger("test: ", &Settings{
+		EnableSyslog:   true,
+		SyslogNetwork:  "udp",
+		SyslogAddress:  pc.LocalAddr().String(),
+		SyslogFacility: "local0",
+		SyslogTag:      "mlogtest",
+		SyslogLevel:    "info",
+	})
+	defer l.Close()
+	if l.Sink("syslog") == nil {
+		t.Fatal("syslog sink should be added")
+	}
+
+	l.Debug("Omitted debug")
+	l.Warn("disk full")
+	l.Error("disk failed")
+
+	buf := make([]byte, 1024)
+	for _, want := range []struct{ pri, msg string }{
+		{"<132>", ": WARN test: disk full\n"},
+		{"<131>", ": EROR test: disk failed\n"},
+	} {
+		pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+		n, _, err := pc.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := string(buf[:n])
+		if !strings.HasPrefix(got, want.pri) || !strings.Contains(got, " mlogtest[") || !strings.HasSuffix(got, want.msg) {
+			t.Errorf("syslog message should match %q %q, is %q", want.pri, want.msg, got)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package mlog
+
+import (
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// settingsSinks is the names of the outputs created by NewLogger from
+// settings, which are replaced on reload.
+var settingsSinks = []string{"console", "file", "syslog"}
+
+// Watcher reloads the settings from a file on SIGHUP, or when the
+// modification time of the file changes, and applies them to the logger.
+type Watcher struct {
+	l    *Logger
+	path string
+
+	mu       sync.Mutex
+	settings *Settings // applied
+	modTime  time.Time
+
+	sig  chan os.Signal
+	stop chan struct{}
+	done chan struct{}
+}
+
+// Watch loads the settings from the file at path, applies them to Default(),
+// and reloads them on changes. See NewWatcher.
+func Watch(path string, interval time.Duration) (*Watcher, error) {
+	return NewWatcher(Default(), path, interval)
+}
+
+// NewWatcher loads the settings from the file at path by LoadSettings, and
+// applies them to l. The settings are reloaded on SIGHUP, and when the
+// modification time of the file changes, checked every interval if positive.
+// An invalid file on reload is logged as error, and the settings applied are
+// kept.
+func NewWatcher(l *Logger, path string, interval time.Duration) (*Watcher, error) {
+	w := &Watcher{
+		l:    l,
+		path: path,
+		sig:  make(chan os.Signal, 1),
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	if err := w.reload(); err != nil {
+		return nil, err
+	}
+	if len(reloadSignals) > 0 {
+		signal.Notify(w.sig, reloadSignals...)
+	}
+	go w.run(interval)
+	return w, nil
+}
+
+func (w *Watcher) run(interval time.Duration) {
+	defer close(w.done)
+
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-w.sig:
+			w.Reload()
+		case <-tick:
+			if fi, err := os.Stat(w.path); err == nil && w.changed(fi.ModTime()) {
+				w.Reload()
+			}
+		}
+	}
+}
+
+func (w *Watcher) changed(modTime time.Time) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return !modTime.Equal(w.modTime)
+}
+
+// Reload loads the settings from the file, and applies them to the logger.
+// The error is logged too, and the settings applied are kept.
+func (w *Watcher) Reload() error {
+	err := w.reload()
+	if err != nil {
+		w.l.Errorw("mlog: reload settings", "path", w.path, "error", err)
+	}
+	return err
+}
+
+func (w *Watcher) reload() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if fi, err := os.Stat(w.path); err == nil {
+		w.modTime = fi.ModTime()
+	}
+	s, err := LoadSettings(w.path)
+	if err != nil {
+		return err
+	}
+	loaded := *s // NewLogger may fill in the settings
+	if w.settings != nil && onlyLevelsChanged(w.settings, &loaded) {
+		w.l.SetLevel("console", s.ConsoleLevel)
+		w.l.SetLevel("file", s.FileLevel)
+		w.l.SetLevel("syslog", s.SyslogLevel)
+	} else if err := w.l.applySettings(s); err != nil {
+		return err
+	}
+	w.settings = &loaded
+	return nil
+}
+
+// Close stops watching.
+func (w *Watcher) Close() {
+	signal.Stop(w.sig)
+	close(w.stop)
+	<-w.done
+}
+
+// onlyLevelsChanged reports whether the settings differ only in levels.
+func onlyLevelsChanged(old, s *Settings) bool {
+	a, b := *old, *s
+	a.ConsoleLevel, b.ConsoleLevel = "", ""
+	a.FileLevel, b.FileLevel = "", ""
+	a.SyslogLevel, b.SyslogLevel = "", ""
+	return reflect.DeepEqual(a, b)
+}
+
+// applySettings replaces the outputs created from settings, sampling and
+// stack trace level of l and all loggers sharing its outputs. The other
+// outputs, hooks and handlers are kept. The outputs replaced are closed.
+// If any output of the settings fails, nothing is replaced, and the error
+// is returned.
+func (l *Logger) applySettings(s *Settings) error {
+	created, err := newSinks(s, true)
+	if err != nil {
+		for _, s := range created {
+			s.Close()
+		}
+		return err
+	}
+	n := NewLogger(l.name, &Settings{StacktraceLevel: s.StacktraceLevel, Sampling: s.Sampling})
+	n.core.sinks = created
+
+	isSettingsSink := func(name string) bool {
+		for _, ss := range settingsSinks {
+			if name == ss {
+				return true
+			}
+		}
+		return false
+	}
+
+	l.core.mu.Lock()
+	var sinks []namedSink
+	var old []Sink
+	for _, s := range l.core.sinks {
+		if isSettingsSink(s.name) {
+			old = append(old, s.Sink)
+		} else {
+			sinks = append(sinks, s)
+		}
+	}
+	l.core.sinks = append(sinks, n.core.sinks...)
+	l.core.sampler = n.core.sampler
+	if l.core.sampler != nil {
+		l.core.sampler.core = l.core
+	}
+	l.core.stack.Store(n.core.stack.Load())
+	l.core.mu.Unlock()
+
+	for _, s := range old {
+		s.Close()
+	}
+	return nil
+}
@@ -0,0 +1,6 @@
+package mlog
+
+import "os"
+
+// reloadSignals is empty, as js has no SIGHUP.
+var reloadSignals []os.Signal
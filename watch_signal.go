@@ -0,0 +1,11 @@
+//go:build !js
+
+package mlog
+
+import (
+	"os"
+	"syscall"
+)
+
+// reloadSignals is the signals reloading the settings of Watcher.
+var reloadSignals = []os.Signal{syscall.SIGHUP}
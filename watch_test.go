@@ -0,0 +1,121 @@
+package mlog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func readString(t *testing.T, path string) string {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestWatcher(t *testing.T) {
+	dir := t.TempDir()
+	conf := filepath.Join(dir, "log.conf")
+	write := func(content string) {
+		if err := os.WriteFile(conf, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a, b := filepath.Join(dir, "a.log"), filepath.Join(dir, "b.log")
+	write("enable_console=false\nenable_file=true\nfile_level=info\nfile_location=" + a)
+
+	l := NewLogger("watch: ", &Settings{})
+	mem := &memSink{}
+	l.AddSink("mem", mem)
+	w, err := NewWatcher(l, conf, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	l.Info("one")
+	write("enable_console=false\nenable_file=true\nfile_level=warn\nfile_location=" + b)
+	if err := w.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	l.Info("two")
+	l.Warn("three")
+
+	write("enable_file=maybe")
+	if err := w.Reload(); err == nil {
+		t.Errorf("invalid settings should fail")
+	}
+	write("enable_console=false\nenable_file=true\nfile_level=debug\nfile_location=" + b)
+	if err := w.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	l.Debug("four")
+	l.Close()
+
+	if got := readString(t, a); !strings.HasSuffix(got, "INFO watch: one\n") {
+		t.Errorf("first file is %q", got)
+	}
+	got := readString(t, b)
+	if strings.Contains(got, "two") || !strings.Contains(got, "three") || !strings.Contains(got, "mlog: reload settings") || !strings.Contains(got, "four") {
+		t.Errorf("second file is %q", got)
+	}
+	if len(mem.entries) != 5 {
+		t.Errorf("other outputs should be kept, has %d entries", len(mem.entries))
+	}
+}
+
+func TestWatcherModTime(t *testing.T) {
+	dir := t.TempDir()
+	conf := filepath.Join(dir, "log.json")
+	os.WriteFile(conf, []byte(`{"EnableConsole": false}`), 0600)
+
+	l := NewLogger("watch: ", &Settings{})
+	w, err := NewWatcher(l, conf, 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	os.WriteFile(conf, []byte(`{"EnableConsole": true, "ConsoleLevel": "fatal"}`), 0600)
+	future := time.Now().Add(time.Minute)
+	os.Chtimes(conf, future, future)
+	for i := 0; i < 100 && l.Sink("console") == nil; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if want, got := "fatal", l.Level("console"); want != got {
+		t.Errorf("settings should be reloaded, level %q is %q", want, got)
+	}
+}
+
+func TestWatcherKeepsOutputsOnError(t *testing.T) {
+	dir := t.TempDir()
+	conf := filepath.Join(dir, "log.conf")
+	write := func(content string) {
+		if err := os.WriteFile(conf, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a := filepath.Join(dir, "a.log")
+	write("enable_console=false\nenable_file=true\nfile_location=" + a)
+
+	l := NewLogger("watch: ", &Settings{})
+	w, err := NewWatcher(l, conf, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	write("enable_console=false\nenable_file=true\nfile_location=" + filepath.Join(dir, "missing", "b.log"))
+	if err := w.Reload(); err == nil {
+		t.Errorf("reload to a missing directory should fail")
+	}
+	l.Info("kept")
+	l.Close()
+
+	if got := readString(t, a); !strings.Contains(got, "INFO watch: kept\n") {
+		t.Errorf("old file should be kept, is %q", got)
+	}
+}